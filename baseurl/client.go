@@ -0,0 +1,114 @@
+package baseurl
+
+import (
+	"encoding/json"
+	"log"
+	"net/url"
+	"sync"
+
+	client "github.com/coredgeio/goecsclient"
+)
+
+// BaseURLClient manages the base urls used by ECS to identify bucket and
+// namespace from host of S3 requests
+type BaseURLClient interface {
+	ListBaseURLs() (*BaseURLListResp, error)
+	GetBaseURL(id string) (*BaseURL, error)
+	BucketS3URL(bucket, namespace string) (string, error)
+}
+
+type baseURLClient struct {
+	apiClient client.DataSession
+
+	// base urls resolved by BucketS3URL, loaded on first use
+	mu       sync.Mutex
+	baseURLs []*BaseURL
+}
+
+func (c *baseURLClient) ListBaseURLs() (*BaseURLListResp, error) {
+	bytes, err := c.apiClient.Get("/object/baseurl", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &BaseURLListResp{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for list base urls", err)
+	}
+	return resp, err
+}
+
+func (c *baseURLClient) GetBaseURL(id string) (*BaseURL, error) {
+	bytes, err := c.apiClient.Get("/object/baseurl/"+id, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &BaseURL{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get base url", err)
+	}
+	return resp, err
+}
+
+// provides the url at which the bucket is addressable over S3. when a
+// base url is configured the url is virtual host style, with namespace in
+// host if the base url is configured so, eg. https://bucket.ns.baseurl.
+// without any base url configured, the url is path style on the data
+// endpoint, eg. https://host:9021/bucket. scheme and port are always of
+// the data endpoint.
+//
+// base urls are looked up once and cached for the lifetime of the client,
+// first base url is used when multiple are configured
+func (c *baseURLClient) BucketS3URL(bucket, namespace string) (string, error) {
+	data, err := url.Parse(c.apiClient.DataEndpoint())
+	if err != nil {
+		return "", err
+	}
+	baseURLs, err := c.getBaseURLs()
+	if err != nil {
+		return "", err
+	}
+	if len(baseURLs) == 0 {
+		return data.JoinPath(bucket).String(), nil
+	}
+	b := baseURLs[0]
+	host := bucket + "." + b.BaseURL
+	if b.NamespaceInHost {
+		host = bucket + "." + namespace + "." + b.BaseURL
+	}
+	if port := data.Port(); port != "" {
+		host += ":" + port
+	}
+	u := url.URL{Scheme: data.Scheme, Host: host}
+	return u.String(), nil
+}
+
+func (c *baseURLClient) getBaseURLs() ([]*BaseURL, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.baseURLs != nil {
+		return c.baseURLs, nil
+	}
+	list, err := c.ListBaseURLs()
+	if err != nil {
+		return nil, err
+	}
+	baseURLs := []*BaseURL{}
+	for _, item := range list.BaseURLs {
+		b, err := c.GetBaseURL(item.ID)
+		if err != nil {
+			return nil, err
+		}
+		baseURLs = append(baseURLs, b)
+	}
+	c.baseURLs = baseURLs
+	return baseURLs, nil
+}
+
+// provides EcsBaseURLClient for given handler to EcsClient
+func GetEcsBaseURLClient(apiClient client.DataSession) BaseURLClient {
+	return &baseURLClient{
+		apiClient: apiClient,
+	}
+}
@@ -0,0 +1,24 @@
+package baseurl
+
+type Link struct {
+	Rel  string `json:"rel,omitempty"`
+	Href string `json:"href,omitempty"`
+}
+
+type BaseURLListResp struct {
+	BaseURLs []struct {
+		ID   string `json:"id,omitempty"`
+		Name string `json:"name,omitempty"`
+		Link Link   `json:"link,omitempty"`
+	} `json:"base_url,omitempty"`
+}
+
+type BaseURL struct {
+	ID      string `json:"id,omitempty"`
+	Name    string `json:"name,omitempty"`
+	BaseURL string `json:"baseurl,omitempty"`
+	// when set, namespace is part of the host in bucket urls, eg.
+	// bucket.namespace.baseurl, otherwise urls are bucket.baseurl
+	NamespaceInHost bool `json:"namespace_in_host,omitempty"`
+	Link            Link `json:"link,omitempty"`
+}
@@ -1,42 +1,126 @@
 package bucket
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	client "github.com/coredgeio/goecsclient"
+	ecserrors "github.com/coredgeio/goecsclient/errors"
+	"github.com/coredgeio/goecsclient/replicationgroup"
 )
 
 type BucketClient interface {
 	GetList(param *BucketListParameters) (*BucketListResp, error)
+	ListUserBuckets(userID, namespace string) (*BucketListResp, error)
+	ListAllBuckets(marker string, limit int) (*BucketListResp, error)
 	Create(req *BucketCreateReq) (*BucketCreateResp, error)
+	EnsureBucket(req *BucketCreateReq) (created bool, err error)
+	GetInfo(name, namespace string) (*Bucket, error)
+	WaitForBucket(ctx context.Context, name, namespace string, timeout time.Duration) error
+	WaitForBucketDeleted(ctx context.Context, name, namespace string, timeout time.Duration) error
 	Delete(name, namespace string) error
+	ForceDelete(name, namespace string) error
 	SetQuota(name string, req *BucketQuotaUpdateReq) error
 	GetBillingInfo(name, namespace, sizeunit string) (*BucketBillingInfoResp, error)
+	SetOwner(name string, req *BucketOwnerUpdateReq) error
+	SetStaleAllowed(name string, req *BucketStaleAllowedUpdateReq) error
+	SetRetention(name string, req *BucketRetentionUpdateReq) error
+	GetRetention(name, namespace string) (int64, error)
+	SetBucketRetentionClass(name, namespace, className string) error
+	GetBucketRetentionClass(name, namespace string) (string, error)
+	SetTags(name string, req *BucketTagsUpdateReq) error
+	Update(name string, req *BucketUpdateReq) error
+	GetVersioning(name, namespace string) (bool, error)
+	SetVersioning(name, namespace string, enabled bool) error
+	GetDefaultGroup(name, namespace string) (string, []GroupPermission, error)
+	SetDefaultGroup(name, namespace, group string, perms []GroupPermission) error
+	GetLifecycle(name, namespace string) ([]LifecycleRule, error)
+	SetLifecycle(name, namespace string, rules []LifecycleRule) error
+	GetObjectLock(name, namespace string) (*BucketObjectLock, error)
+	SetObjectLock(name, namespace string, mode string, days int) error
+	GetCORS(name, namespace string) ([]CORSRule, error)
+	SetCORS(name, namespace string, rules []CORSRule) error
+	GetBucketLogging(name, namespace string) (*LoggingTarget, error)
+	SetBucketLogging(name, namespace, targetBucket, prefix string) error
+	GetPolicy(name, namespace string) ([]byte, error)
+	SetPolicy(name, namespace string, policyJSON []byte) error
+	GetACL(name, namespace string) (*BucketACL, error)
+	EffectiveBucketPermission(name, namespace, userID string) ([]string, error)
+	DiffBucket(desired BucketSpec) (*BucketDiff, error)
+	UpdateBucketSearchMetadata(name, namespace string, keys []SearchKey) error
+	GetUserUsage(userID, namespace string) (*UserUsage, error)
+	GetBucketReplicationStatus(name, namespace string) (*ReplicationStatus, error)
 }
 
 type bucketClient struct {
-	apiClient client.EcsClient
+	apiClient client.Session
+
+	// when set, replication group of the bucket is validated before
+	// creation
+	rgCache *replicationgroup.Cache
+
+	// provides custom groups of an object user, for resolving effective
+	// permissions
+	groupResolver GroupResolver
+}
+
+// earliest ECS version allowing search metadata of an existing bucket to
+// be updated, older versions accept it only at creation
+const minSearchMetadataUpdateVersion = "3.5.0.0"
+
+const (
+	waitBaseBackoff = 500 * time.Millisecond
+	waitMaxBackoff  = 10 * time.Second
+)
+
+// Option allows configuring optional behaviour of bucket client
+type Option func(*bucketClient)
+
+// validates that the replication group (vpool) requested for a bucket
+// exists before creating it, failing with ErrReplicationGroupNotFound
+// otherwise. replication groups are looked up using the given cache which
+// can be shared with other clients. vpool can also be given by the name
+// of the replication group, which is resolved to its id
+func WithReplicationGroupValidation(cache *replicationgroup.Cache) Option {
+	return func(c *bucketClient) {
+		c.rgCache = cache
+	}
+}
+
+// GroupResolver provides the custom groups the object user of namespace
+// is a member of
+type GroupResolver func(userID, namespace string) ([]string, error)
+
+// resolves custom group membership of users using the given resolver
+// while computing EffectiveBucketPermission, eg. by looking up the
+// groups in the directory ECS is integrated with
+func WithGroupResolver(resolver GroupResolver) Option {
+	return func(c *bucketClient) {
+		c.groupResolver = resolver
+	}
 }
 
 func (c *bucketClient) GetList(param *BucketListParameters) (*BucketListResp, error) {
 	var query url.Values
-	if param != nil && (param.Namespace != "" || param.Marker != "" || param.Limit != 0 || param.Name != "") {
-		query = url.Values{}
-		if param.Namespace != "" {
-			query.Add("namespace", param.Namespace)
-		}
-		if param.Marker != "" {
-			query.Add("marker", param.Marker)
-		}
-		if param.Limit != 0 {
-			query.Add("limit", strconv.Itoa(param.Limit))
-		}
-		if param.Name != "" {
-			query.Add("name", param.Name)
+	if param != nil {
+		if param.Namespace != "" || param.Name != "" {
+			query = url.Values{}
+			if param.Namespace != "" {
+				query.Add("namespace", param.Namespace)
+			}
+			if param.Name != "" {
+				query.Add("name", param.Name)
+			}
 		}
+		query = param.AddToQuery(query, client.MgmtPageParams)
 	}
 
 	bytes, err := c.apiClient.Get("/object/bucket", query, nil)
@@ -51,24 +135,299 @@ func (c *bucketClient) GetList(param *BucketListParameters) (*BucketListResp, er
 	return resp, err
 }
 
+// lists all the buckets in namespace owned by the given object user.
+//
+// ECS does not support filtering bucket list on owner, so this pages
+// through every bucket of the namespace and filters on the client side,
+// cost of the call grows with the number of buckets in namespace and not
+// with the number of buckets owned by the user
+func (c *bucketClient) ListUserBuckets(userID, namespace string) (*BucketListResp, error) {
+	param := &BucketListParameters{Namespace: namespace}
+	result := &BucketListResp{}
+	for {
+		resp, err := c.GetList(param)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range resp.Buckets {
+			if b.Owner == userID {
+				result.Buckets = append(result.Buckets, b)
+			}
+		}
+		if resp.NextMarker == "" || resp.NextMarker == param.Marker {
+			break
+		}
+		param.Marker = resp.NextMarker
+	}
+	return result, nil
+}
+
+// sums the billing info of the buckets owned by the user, for attributing
+// storage to the owner rather than the bucket. billing info of ECS is
+// sampled periodically, hence lags behind recent writes. sizes are read
+// in KB, the finest unit ECS reports
+func (c *bucketClient) GetUserUsage(userID, namespace string) (*UserUsage, error) {
+	buckets, err := c.ListUserBuckets(userID, namespace)
+	if err != nil {
+		return nil, err
+	}
+	usage := &UserUsage{
+		UserID:    userID,
+		Namespace: namespace,
+		Buckets:   len(buckets.Buckets),
+	}
+	for _, b := range buckets.Buckets {
+		info, err := c.GetBillingInfo(b.Name, namespace, "KB")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get billing info of bucket %s: %w", b.Name, err)
+		}
+		if info.TotalSize != "" {
+			kb, err := strconv.ParseFloat(info.TotalSize, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid size %q of bucket %s: %w", info.TotalSize, b.Name, err)
+			}
+			usage.TotalBytes += int64(kb * 1024)
+		}
+		usage.TotalObjects += int64(info.TotalObjects)
+	}
+	return usage, nil
+}
+
+// lists buckets across all the namespaces, requires system admin
+// privileges. Namespace of every bucket identifies the namespace it
+// belongs to.
+//
+// ECS lists buckets only per namespace, so this walks the namespaces in
+// order of their name, listing buckets of each. marker is the NextMarker
+// of previous response, empty for first page, limit of zero lists all
+// the buckets. if the namespace being listed gets deleted between pages,
+// listing resumes at the next namespace
+func (c *bucketClient) ListAllBuckets(marker string, limit int) (*BucketListResp, error) {
+	namespaces, err := c.getNamespaceNames()
+	if err != nil {
+		return nil, err
+	}
+	// marker carries namespace along with the bucket marker within it
+	startNs, nsMarker, _ := strings.Cut(marker, "/")
+	result := &BucketListResp{}
+	for _, ns := range namespaces {
+		if ns < startNs {
+			continue
+		}
+		if ns != startNs {
+			// bucket marker applies only to the namespace it came from
+			nsMarker = ""
+		}
+		if limit != 0 && len(result.Buckets) >= limit {
+			// page filled exactly at the end of previous namespace
+			result.NextMarker = ns + "/"
+			return result, nil
+		}
+		param := &BucketListParameters{Namespace: ns}
+		param.Marker = nsMarker
+		for {
+			if limit != 0 {
+				param.Limit = limit - len(result.Buckets)
+			}
+			resp, err := c.GetList(param)
+			if err != nil {
+				return nil, err
+			}
+			result.Buckets = append(result.Buckets, resp.Buckets...)
+			if resp.NextMarker == "" || resp.NextMarker == param.Marker {
+				break
+			}
+			if limit != 0 && len(result.Buckets) >= limit {
+				result.NextMarker = ns + "/" + resp.NextMarker
+				return result, nil
+			}
+			param.Marker = resp.NextMarker
+		}
+	}
+	return result, nil
+}
+
+// provides names of all the namespaces sorted by name, paging through
+// the namespace list. namespace client builds on top of bucket client, so
+// namespaces are listed here directly instead of using namespace client
+func (c *bucketClient) getNamespaceNames() ([]string, error) {
+	var names []string
+	query := url.Values{}
+	for {
+		bytes, err := c.apiClient.Get("/object/namespaces", query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp := &namespaceListResp{}
+		if err = json.Unmarshal(bytes, resp); err != nil {
+			log.Println("failed to decode response for get namespace list", err)
+			return nil, err
+		}
+		for _, ns := range resp.Namespaces {
+			names = append(names, ns.Name)
+		}
+		if resp.NextMarker == "" || resp.NextMarker == query.Get("marker") {
+			break
+		}
+		query.Set("marker", resp.NextMarker)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// empty head type is valid and results in ECS using its default of s3
+func validateHeadType(h HeadType) error {
+	switch h {
+	case "", HeadTypeS3, HeadTypeSwift, HeadTypeAtmos, HeadTypeCAS:
+		return nil
+	}
+	return ecserrors.Wrap("invalid head type " + string(h) + ", must be one of s3, swift, atmos or cas")
+}
+
 func (c *bucketClient) Create(req *BucketCreateReq) (*BucketCreateResp, error) {
+	if err := validateHeadType(req.HeadType); err != nil {
+		return nil, err
+	}
+	if c.rgCache != nil && req.Vpool != "" {
+		rg, err := c.rgCache.Resolve(req.Vpool)
+		if err != nil {
+			return nil, err
+		}
+		if rg.ID != req.Vpool {
+			resolved := *req
+			resolved.Vpool = rg.ID
+			req = &resolved
+		}
+	}
 	data, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
 
-	bytes, err := c.apiClient.Post("/object/bucket", data, nil, nil)
+	r, err := c.apiClient.Create("/object/bucket", data, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	resp := &BucketCreateResp{}
-	if err = json.Unmarshal(bytes, resp); err != nil {
+	if err = json.Unmarshal(r.Body, resp); err != nil {
 		log.Println("failed to decode response for create bucket", err)
 	}
+	resp.Location = r.Header.Get("Location")
 	return resp, err
 }
 
+// creates the bucket only if it does not exist already, returns true if
+// the bucket was created as part of this call.
+//
+// if the bucket gets created by someone else between the existence check
+// and create, the resulting conflict is treated as bucket already exists
+func (c *bucketClient) EnsureBucket(req *BucketCreateReq) (bool, error) {
+	_, err := c.GetInfo(req.Name, req.Namespace)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, ecserrors.ErrNotFound) {
+		return false, err
+	}
+	_, err = c.Create(req)
+	if err != nil {
+		if errors.Is(err, ecserrors.ErrCreatedByEarlierAttempt) {
+			return true, nil
+		}
+		if errors.Is(err, ecserrors.ErrAlreadyExists) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *bucketClient) GetInfo(name, namespace string) (*Bucket, error) {
+	var query url.Values
+	if namespace != "" {
+		query = url.Values{}
+		query.Add("namespace", namespace)
+	}
+	bytes, err := c.apiClient.Get("/object/bucket/"+name+"/info", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &Bucket{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get bucket info", err)
+	}
+	return resp, err
+}
+
+// polls bucket info till it succeeds, eg. while a newly created bucket
+// propagates across a geo replicated setup. polls are made with backoff
+// in between, till the timeout or the context expires. zero timeout
+// waits as long as the context allows
+func (c *bucketClient) WaitForBucket(ctx context.Context, name, namespace string, timeout time.Duration) error {
+	err := pollBucket(ctx, timeout, func() (bool, error) {
+		_, err := c.GetInfo(name, namespace)
+		return err == nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("bucket %s not available: %w", name, err)
+	}
+	return nil
+}
+
+// polls bucket info till the bucket is not found, eg. while ECS empties
+// a force deleted bucket in background. polls are made the same way as
+// WaitForBucket, errors other than not found are retried
+func (c *bucketClient) WaitForBucketDeleted(ctx context.Context, name, namespace string, timeout time.Duration) error {
+	err := pollBucket(ctx, timeout, func() (bool, error) {
+		_, err := c.GetInfo(name, namespace)
+		if errors.Is(err, ecserrors.ErrNotFound) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		return fmt.Errorf("bucket %s not deleted: %w", name, err)
+	}
+	return nil
+}
+
+// invokes check with backoff in between till it is done, or the timeout
+// or context expires. zero timeout waits as long as the context allows
+func pollBucket(ctx context.Context, timeout time.Duration, check func() (bool, error)) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	delay := waitBaseBackoff
+	for {
+		done, err := check()
+		if done {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return fmt.Errorf("%w, last error: %v", ctx.Err(), err)
+			}
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > waitMaxBackoff {
+			delay = waitMaxBackoff
+		}
+	}
+}
+
+// deletes the bucket, which has to be empty. despite the name of the
+// deactivate endpoint the delete is permanent, ECS has no soft delete or
+// recovery window for buckets, so deleted buckets can neither be listed
+// nor recovered. objects needing protection against accidental deletes
+// are to be guarded using retention or object lock instead
 func (c *bucketClient) Delete(name, namespace string) error {
 	var query url.Values
 	if namespace != "" {
@@ -83,12 +442,37 @@ func (c *bucketClient) Delete(name, namespace string) error {
 	return nil
 }
 
+// deletes the bucket along with all the objects in it, objects are
+// removed by ECS in background after which the bucket is deleted
+func (c *bucketClient) ForceDelete(name, namespace string) error {
+	query := url.Values{}
+	if namespace != "" {
+		query.Add("namespace", namespace)
+	}
+	query.Add("emptyBucket", "true")
+	_, err := c.apiClient.Post("/object/bucket/"+name+"/deactivate", nil, query, nil)
+	return err
+}
+
+// sets hard and soft quota of the bucket, see BucketQuotaUpdateReq for
+// meaning of the limits. zero limits are sent as QuotaUnlimited since ECS
+// otherwise treats zero as a quota of zero GB
 func (c *bucketClient) SetQuota(name string, req *BucketQuotaUpdateReq) error {
-	data, err := json.Marshal(req)
+	q := *req
+	if q.BlockSize <= 0 {
+		q.BlockSize = QuotaUnlimited
+	}
+	if q.NotificationSize <= 0 {
+		q.NotificationSize = QuotaUnlimited
+	}
+	if q.BlockSize != QuotaUnlimited && q.NotificationSize > q.BlockSize {
+		return ecserrors.Wrap(fmt.Sprintf("notification size %d exceeds block size %d", q.NotificationSize, q.BlockSize))
+	}
+	data, err := json.Marshal(&q)
 	if err != nil {
 		return err
 	}
-	_, err = c.apiClient.Put("/object/bucket/"+name+"/quota", data, nil)
+	_, err = c.apiClient.Put("/object/bucket/"+name+"/quota", data, nil, nil)
 	return err
 }
 
@@ -110,9 +494,771 @@ func (c *bucketClient) GetBillingInfo(name, namespace, sizeunit string) (*Bucket
 	return resp, err
 }
 
+func (c *bucketClient) SetOwner(name string, req *BucketOwnerUpdateReq) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Post("/object/bucket/"+name+"/owner", data, nil, nil)
+	return err
+}
+
+func (c *bucketClient) SetStaleAllowed(name string, req *BucketStaleAllowedUpdateReq) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Post("/object/bucket/"+name+"/isstaleallowed", data, nil, nil)
+	return err
+}
+
+func (c *bucketClient) SetRetention(name string, req *BucketRetentionUpdateReq) error {
+	if req.Period < 0 && req.Period != RetentionInfinite {
+		return ecserrors.Wrap("retention period cannot be negative, use RetentionInfinite for infinite retention")
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/object/bucket/"+name+"/retention", data, nil, nil)
+	return err
+}
+
+// assigns the retention class of the namespace to the bucket, the retention
+// period of the bucket follows the period of the class. class is looked up
+// in the namespace first, failing with ErrNotFound if it does not exist
+func (c *bucketClient) SetBucketRetentionClass(name, namespace, className string) error {
+	if namespace == "" || className == "" {
+		return ecserrors.Wrap("namespace and retention class are required")
+	}
+	bytes, err := c.apiClient.Get("/object/namespaces/namespace/"+namespace+"/retention", nil, nil)
+	if err != nil {
+		return err
+	}
+	classes := &retentionClassListResp{}
+	if err = json.Unmarshal(bytes, classes); err != nil {
+		log.Println("failed to decode response for get namespace retention classes", err)
+		return err
+	}
+	var class *RetentionClass
+	for i := range classes.RetentionClasses {
+		if classes.RetentionClasses[i].Name == className {
+			class = &classes.RetentionClasses[i]
+			break
+		}
+	}
+	if class == nil {
+		return fmt.Errorf("retention class %s not found in namespace %s: %w", className, namespace, ecserrors.ErrNotFound)
+	}
+	data, err := json.Marshal(&bucketRetentionClassUpdateReq{
+		Namespace:      namespace,
+		Period:         class.Period,
+		RetentionClass: class.Name,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/object/bucket/"+name+"/retention", data, nil, nil)
+	return err
+}
+
+// provides the retention class assigned to the bucket, empty if the
+// retention of bucket is set as a raw period
+func (c *bucketClient) GetBucketRetentionClass(name, namespace string) (string, error) {
+	var query url.Values
+	if namespace != "" {
+		query = url.Values{}
+		query.Add("namespace", namespace)
+	}
+	bytes, err := c.apiClient.Get("/object/bucket/"+name+"/retention", query, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp := &bucketRetentionResp{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get bucket retention", err)
+		return "", err
+	}
+	return resp.RetentionClass, nil
+}
+
+// provides default retention period of the bucket in seconds,
+// RetentionInfinite if objects are retained forever
+func (c *bucketClient) GetRetention(name, namespace string) (int64, error) {
+	var query url.Values
+	if namespace != "" {
+		query = url.Values{}
+		query.Add("namespace", namespace)
+	}
+	bytes, err := c.apiClient.Get("/object/bucket/"+name+"/retention", query, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp := &bucketRetentionResp{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get bucket retention", err)
+		return 0, err
+	}
+	if resp.Period < 0 {
+		return RetentionInfinite, nil
+	}
+	return resp.Period, nil
+}
+
+func (c *bucketClient) SetTags(name string, req *BucketTagsUpdateReq) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/object/bucket/"+name+"/tags", data, nil, nil)
+	return err
+}
+
+// applies all the settings provided as part of update request, settings
+// which are not provided are left untouched on the bucket.
+//
+// ECS does not offer a single endpoint for these, so each setting is
+// applied using its own api call. all the settings are attempted even if
+// one of them fails and the returned error names every setting that
+// could not be applied
+func (c *bucketClient) Update(name string, req *BucketUpdateReq) error {
+	if req == nil {
+		return nil
+	}
+	var errs []error
+	if req.Owner != "" {
+		err := c.SetOwner(name, &BucketOwnerUpdateReq{
+			Namespace: req.Namespace,
+			NewOwner:  req.Owner,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to update owner: %w", err))
+		}
+	}
+	if req.IsStaleAllowed != nil {
+		err := c.SetStaleAllowed(name, &BucketStaleAllowedUpdateReq{
+			Namespace:      req.Namespace,
+			IsStaleAllowed: *req.IsStaleAllowed,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to update stale allowed: %w", err))
+		}
+	}
+	if req.Quota != nil {
+		quota := *req.Quota
+		if quota.Namespace == "" {
+			quota.Namespace = req.Namespace
+		}
+		if err := c.SetQuota(name, &quota); err != nil {
+			errs = append(errs, fmt.Errorf("failed to update quota: %w", err))
+		}
+	}
+	if req.Retention != nil {
+		err := c.SetRetention(name, &BucketRetentionUpdateReq{
+			Namespace: req.Namespace,
+			Period:    *req.Retention,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to update retention: %w", err))
+		}
+	}
+	if req.TagSet != nil {
+		err := c.SetTags(name, &BucketTagsUpdateReq{
+			Namespace: req.Namespace,
+			TagSet:    req.TagSet,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to update tags: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reads the versioning state of the bucket using the management api
+// endpoint /object/bucket/{name}/versioning, returns true only when
+// versioning is enabled, a suspended or never enabled versioning is
+// reported as false
+func (c *bucketClient) GetVersioning(name, namespace string) (bool, error) {
+	var query url.Values
+	if namespace != "" {
+		query = url.Values{}
+		query.Add("namespace", namespace)
+	}
+	bytes, err := c.apiClient.Get("/object/bucket/"+name+"/versioning", query, nil)
+	if err != nil {
+		return false, err
+	}
+
+	resp := &BucketVersioning{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get bucket versioning", err)
+		return false, err
+	}
+	return resp.Status == VersioningEnabled, nil
+}
+
+// enables or suspends versioning of the bucket using the management api
+// endpoint /object/bucket/{name}/versioning. once enabled, versioning
+// can only be suspended and not disabled
+func (c *bucketClient) SetVersioning(name, namespace string, enabled bool) error {
+	req := &BucketVersioning{
+		Namespace: namespace,
+		Status:    VersioningSuspended,
+	}
+	if enabled {
+		req.Status = VersioningEnabled
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/object/bucket/"+name+"/versioning", data, nil, nil)
+	return err
+}
+
+// provides the default group of the bucket along with the permissions
+// the group gets on objects and directories created in the bucket
+func (c *bucketClient) GetDefaultGroup(name, namespace string) (string, []GroupPermission, error) {
+	b, err := c.GetInfo(name, namespace)
+	if err != nil {
+		return "", nil, err
+	}
+	var perms []GroupPermission
+	granted := map[GroupPermission]bool{
+		GroupFileRead:    b.DefaultGroupFileReadPermission,
+		GroupFileWrite:   b.DefaultGroupFileWritePermission,
+		GroupFileExecute: b.DefaultGroupFileExecutePermission,
+		GroupDirRead:     b.DefaultGroupDirReadPermission,
+		GroupDirWrite:    b.DefaultGroupDirWritePermission,
+		GroupDirExecute:  b.DefaultGroupDirExecutePermission,
+	}
+	for _, perm := range allGroupPermissions {
+		if granted[perm] {
+			perms = append(perms, perm)
+		}
+	}
+	return b.DefaultGroup, perms, nil
+}
+
+// sets the default group of the bucket, applicable to filesystem enabled
+// buckets. the group gets exactly the given permissions on objects and
+// directories created in the bucket, permissions not listed are revoked
+func (c *bucketClient) SetDefaultGroup(name, namespace, group string, perms []GroupPermission) error {
+	req := &BucketDefaultGroupUpdateReq{
+		Namespace:    namespace,
+		DefaultGroup: group,
+	}
+	for _, perm := range perms {
+		switch perm {
+		case GroupFileRead:
+			req.DefaultGroupFileReadPermission = true
+		case GroupFileWrite:
+			req.DefaultGroupFileWritePermission = true
+		case GroupFileExecute:
+			req.DefaultGroupFileExecutePermission = true
+		case GroupDirRead:
+			req.DefaultGroupDirReadPermission = true
+		case GroupDirWrite:
+			req.DefaultGroupDirWritePermission = true
+		case GroupDirExecute:
+			req.DefaultGroupDirExecutePermission = true
+		default:
+			return ecserrors.Wrap("invalid default group permission " + string(perm))
+		}
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/object/bucket/"+name+"/defaultGroup", data, nil, nil)
+	return err
+}
+
+// provides the object expiration rules of the bucket using management
+// api endpoint /object/bucket/{name}/lifecycle
+func (c *bucketClient) GetLifecycle(name, namespace string) ([]LifecycleRule, error) {
+	var query url.Values
+	if namespace != "" {
+		query = url.Values{}
+		query.Add("namespace", namespace)
+	}
+	bytes, err := c.apiClient.Get("/object/bucket/"+name+"/lifecycle", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &BucketLifecycle{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get bucket lifecycle", err)
+		return nil, err
+	}
+	return resp.Rules, nil
+}
+
+// replaces the object expiration rules of the bucket using management
+// api endpoint /object/bucket/{name}/lifecycle
+func (c *bucketClient) SetLifecycle(name, namespace string, rules []LifecycleRule) error {
+	if len(rules) == 0 {
+		return ecserrors.Wrap("at least one lifecycle rule is required")
+	}
+	for _, rule := range rules {
+		if rule.ExpirationDays <= 0 {
+			return ecserrors.Wrap("lifecycle rule " + rule.ID + " must expire objects after at least a day")
+		}
+	}
+	data, err := json.Marshal(&BucketLifecycle{
+		Namespace: namespace,
+		Rules:     rules,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/object/bucket/"+name+"/lifecycle", data, nil, nil)
+	return err
+}
+
+// provides the object lock configuration of the bucket, mode of the
+// default retention is empty if object lock is not enabled
+func (c *bucketClient) GetObjectLock(name, namespace string) (*BucketObjectLock, error) {
+	var query url.Values
+	if namespace != "" {
+		query = url.Values{}
+		query.Add("namespace", namespace)
+	}
+	bytes, err := c.apiClient.Get("/object/bucket/"+name+"/objectLockConfig", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &BucketObjectLock{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get bucket object lock", err)
+	}
+	return resp, err
+}
+
+// enables object lock (WORM) on the bucket with default retention of
+// given days in GOVERNANCE or COMPLIANCE mode. object lock once enabled
+// cannot be disabled, and retention in COMPLIANCE mode cannot be
+// shortened by anyone
+func (c *bucketClient) SetObjectLock(name, namespace string, mode string, days int) error {
+	if mode != ObjectLockModeGovernance && mode != ObjectLockModeCompliance {
+		return ecserrors.Wrap("invalid object lock mode " + mode + ", must be " +
+			ObjectLockModeGovernance + " or " + ObjectLockModeCompliance)
+	}
+	if days <= 0 {
+		return ecserrors.Wrap("object lock retention must be at least a day")
+	}
+	req := &BucketObjectLock{
+		Namespace:         namespace,
+		ObjectLockEnabled: ObjectLockEnabled,
+	}
+	req.Rule.DefaultRetention.Mode = mode
+	req.Rule.DefaultRetention.Days = days
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/object/bucket/"+name+"/objectLockConfig", data, nil, nil)
+	return err
+}
+
+func (c *bucketClient) GetCORS(name, namespace string) ([]CORSRule, error) {
+	var query url.Values
+	if namespace != "" {
+		query = url.Values{}
+		query.Add("namespace", namespace)
+	}
+	bytes, err := c.apiClient.Get("/object/bucket/"+name+"/cors", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &BucketCORS{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get bucket cors", err)
+		return nil, err
+	}
+	return resp.Rules, nil
+}
+
+// replaces the CORS rules of the bucket, every rule needs to allow at
+// least one origin and method
+func (c *bucketClient) SetCORS(name, namespace string, rules []CORSRule) error {
+	for i, rule := range rules {
+		if len(rule.AllowedOrigins) == 0 || len(rule.AllowedMethods) == 0 {
+			return ecserrors.Wrap("cors rule " + strconv.Itoa(i) + " must allow at least one origin and method")
+		}
+	}
+	data, err := json.Marshal(&BucketCORS{
+		Namespace: namespace,
+		Rules:     rules,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/object/bucket/"+name+"/cors", data, nil, nil)
+	return err
+}
+
+// provides the target to which access logs of the bucket are delivered,
+// nil if access logging is not enabled
+func (c *bucketClient) GetBucketLogging(name, namespace string) (*LoggingTarget, error) {
+	var query url.Values
+	if namespace != "" {
+		query = url.Values{}
+		query.Add("namespace", namespace)
+	}
+	bytes, err := c.apiClient.Get("/object/bucket/"+name+"/logging", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &BucketLogging{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get bucket logging", err)
+		return nil, err
+	}
+	if resp.LoggingEnabled == nil || resp.LoggingEnabled.TargetBucket == "" {
+		return nil, nil
+	}
+	return resp.LoggingEnabled, nil
+}
+
+// enables access logging of the bucket, delivering the logs to
+// targetBucket of the same namespace with keys starting with prefix.
+// target bucket is looked up first, failing with ErrNotFound if it does
+// not exist. a bucket cannot log to itself, since every log delivery
+// would be logged again
+func (c *bucketClient) SetBucketLogging(name, namespace, targetBucket, prefix string) error {
+	if targetBucket == "" {
+		return ecserrors.Wrap("target bucket is required")
+	}
+	if targetBucket == name {
+		return ecserrors.Wrap("bucket " + name + " cannot log access to itself")
+	}
+	if _, err := c.GetInfo(targetBucket, namespace); err != nil {
+		return fmt.Errorf("failed to get target bucket %s: %w", targetBucket, err)
+	}
+	data, err := json.Marshal(&BucketLogging{
+		Namespace: namespace,
+		LoggingEnabled: &LoggingTarget{
+			TargetBucket: targetBucket,
+			TargetPrefix: prefix,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/object/bucket/"+name+"/logging", data, nil, nil)
+	return err
+}
+
+// provides the bucket policy document, fails with error matching
+// ErrNotFound if bucket has no policy set
+func (c *bucketClient) GetPolicy(name, namespace string) ([]byte, error) {
+	var query url.Values
+	if namespace != "" {
+		query = url.Values{}
+		query.Add("namespace", namespace)
+	}
+	bytes, err := c.apiClient.Get("/object/bucket/"+name+"/policy", query, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(bytes))) == 0 {
+		return nil, ecserrors.ErrNotFound
+	}
+	return bytes, nil
+}
+
+// sets the bucket policy document, replacing the existing one if any
+func (c *bucketClient) SetPolicy(name, namespace string, policyJSON []byte) error {
+	if !json.Valid(policyJSON) {
+		return ecserrors.Wrap("bucket policy is not a valid json document")
+	}
+	var query url.Values
+	if namespace != "" {
+		query = url.Values{}
+		query.Add("namespace", namespace)
+	}
+	_, err := c.apiClient.Put("/object/bucket/"+name+"/policy", policyJSON, query, nil)
+	return err
+}
+
+func (c *bucketClient) GetACL(name, namespace string) (*BucketACL, error) {
+	var query url.Values
+	if namespace != "" {
+		query = url.Values{}
+		query.Add("namespace", namespace)
+	}
+	bytes, err := c.apiClient.Get("/object/bucket/"+name+"/acl", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &BucketACL{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get bucket acl", err)
+	}
+	return resp, err
+}
+
+// provides the permissions the object user effectively has on the
+// bucket, sorted by name.
+//
+// permissions are the union of the ones granted to the user directly, the
+// ones granted to the predefined public and all_users groups which every
+// object user is a member of and the ones granted to custom groups of the
+// user. owner of the bucket always has full_control. explicit none grants
+// no permission and does not revoke the ones granted by other entries,
+// same as ECS.
+//
+// ECS does not expose custom group membership, it is resolved using the
+// resolver set with WithGroupResolver. without a resolver, if the ACL has
+// custom group entries the permissions resolved otherwise are returned
+// along with ErrCustomGroupUnresolved, since they may be understated
+func (c *bucketClient) EffectiveBucketPermission(name, namespace, userID string) ([]string, error) {
+	acl, err := c.GetACL(name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	granted := map[string]bool{}
+	grant := func(perms []string) {
+		for _, p := range perms {
+			if p != PermissionNone {
+				granted[p] = true
+			}
+		}
+	}
+	if acl.Acl.Owner == userID {
+		granted[PermissionFullControl] = true
+	}
+	for _, u := range acl.Acl.UserAcl {
+		if u.User == userID {
+			grant(u.Permission)
+		}
+	}
+	for _, g := range acl.Acl.GroupAcl {
+		if g.Group == GroupPublic || g.Group == GroupAllUsers {
+			grant(g.Permission)
+		}
+	}
+	var unresolved error
+	if len(acl.Acl.CustomGroupAcl) != 0 {
+		if c.groupResolver == nil {
+			unresolved = ecserrors.ErrCustomGroupUnresolved
+		} else {
+			groups, err := c.groupResolver(userID, namespace)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve groups of user %s: %w", userID, err)
+			}
+			member := map[string]bool{}
+			for _, g := range groups {
+				member[g] = true
+			}
+			for _, g := range acl.Acl.CustomGroupAcl {
+				if member[g.Group] {
+					grant(g.Permission)
+				}
+			}
+		}
+	}
+	perms := []string{}
+	for p := range granted {
+		perms = append(perms, p)
+	}
+	sort.Strings(perms)
+	return perms, unresolved
+}
+
+// compares the current settings of the bucket against the desired spec,
+// providing the settings which differ along with an update applying them,
+// for planning the changes before applying. tags and ACL entries are
+// compared irrespective of their order. diff has no changes when the
+// bucket is in sync
+func (c *bucketClient) DiffBucket(desired BucketSpec) (*BucketDiff, error) {
+	ns := desired.Namespace
+	info, err := c.GetInfo(desired.Name, ns)
+	if err != nil {
+		return nil, err
+	}
+	diff := &BucketDiff{
+		Update: BucketUpdateReq{Namespace: ns},
+	}
+	add := func(field string, current, want interface{}) {
+		diff.Changes = append(diff.Changes, FieldChange{
+			Field:   field,
+			Current: fmt.Sprint(current),
+			Desired: fmt.Sprint(want),
+		})
+	}
+
+	if desired.Owner != "" && desired.Owner != info.Owner {
+		add("owner", info.Owner, desired.Owner)
+		diff.Update.Owner = desired.Owner
+	}
+	if desired.IsStaleAllowed != nil && *desired.IsStaleAllowed != info.IsStaleAllowed {
+		add("is_stale_allowed", info.IsStaleAllowed, *desired.IsStaleAllowed)
+		diff.Update.IsStaleAllowed = desired.IsStaleAllowed
+	}
+	if q := desired.Quota; q != nil {
+		block, notify := normalizeQuota(q.BlockSize), normalizeQuota(q.NotificationSize)
+		curBlock, curNotify := normalizeQuota(int64(info.BlockSize)), normalizeQuota(int64(info.NotificationSize))
+		if block != curBlock {
+			add("block_size", curBlock, block)
+		}
+		if notify != curNotify {
+			add("notification_size", curNotify, notify)
+		}
+		if block != curBlock || notify != curNotify {
+			diff.Update.Quota = q
+		}
+	}
+	if desired.Retention != nil {
+		period, err := c.GetRetention(desired.Name, ns)
+		if err != nil {
+			return nil, err
+		}
+		if period != *desired.Retention {
+			add("retention", period, *desired.Retention)
+			diff.Update.Retention = desired.Retention
+		}
+	}
+	if desired.TagSet != nil {
+		current := make([]Tag, 0, len(info.TagSet))
+		for _, t := range info.TagSet {
+			current = append(current, Tag{Key: t.Key, Value: t.Value})
+		}
+		if !sameTags(current, desired.TagSet) {
+			add("tags", current, desired.TagSet)
+			diff.Update.TagSet = desired.TagSet
+		}
+	}
+	if desired.UserAcl != nil {
+		acl, err := c.GetACL(desired.Name, ns)
+		if err != nil {
+			return nil, err
+		}
+		current := userAclMap(acl.Acl.UserAcl)
+		want := userAclMap(desired.UserAcl)
+		if fmt.Sprint(current) != fmt.Sprint(want) {
+			add("user_acl", current, want)
+		}
+	}
+	return diff, nil
+}
+
+// zero and QuotaUnlimited both disable the quota
+func normalizeQuota(size int64) int64 {
+	if size <= 0 {
+		return QuotaUnlimited
+	}
+	return size
+}
+
+func sameTags(a, b []Tag) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	tags := map[Tag]int{}
+	for _, t := range a {
+		tags[t]++
+	}
+	for _, t := range b {
+		if tags[t] == 0 {
+			return false
+		}
+		tags[t]--
+	}
+	return true
+}
+
+// permissions of every user sorted, maps are printed in key order which
+// keeps the comparison independent of the order of entries
+func userAclMap(acl []UserAcl) map[string][]string {
+	m := map[string][]string{}
+	for _, a := range acl {
+		perms := append(m[a.User], a.Permission...)
+		sort.Strings(perms)
+		m[a.User] = perms
+	}
+	return m
+}
+
+// replaces the metadata keys indexed for search on an existing bucket.
+// fails with ErrUnsupported on ECS versions allowing them to be set only
+// while creating the bucket, or if the client passed to GetEcsBucketClient
+// does not provide the version of ECS
+func (c *bucketClient) UpdateBucketSearchMetadata(name, namespace string, keys []SearchKey) error {
+	for _, k := range keys {
+		if k.Type != SearchKeySystem && k.Type != SearchKeyUser {
+			return ecserrors.Wrap("invalid search key type " + k.Type + ", must be System or User")
+		}
+		if k.Type == SearchKeyUser && k.Datatype == "" {
+			return ecserrors.Wrap("datatype is required for user search key " + k.Name)
+		}
+	}
+	if err := client.RequireVersion(c.apiClient, minSearchMetadataUpdateVersion); err != nil {
+		return err
+	}
+	data, err := json.Marshal(&bucketSearchMetadataUpdateReq{
+		Namespace:      namespace,
+		SearchMetadata: keys,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/object/bucket/"+name+"/searchmetadata", data, nil, nil)
+	return err
+}
+
+// provides the replication state of the bucket to the remote zones. ECS
+// tracks replication per replication group and not per bucket, so this
+// reports the links of the replication group of the bucket. a bucket is
+// reported fully replicated only when nothing is pending on any link, an
+// idle bucket may therefore wait on data of other buckets of the group
+func (c *bucketClient) GetBucketReplicationStatus(name, namespace string) (*ReplicationStatus, error) {
+	info, err := c.GetInfo(name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := c.apiClient.Get("/dashboard/replicationgroups/"+info.Vpool+"/rglinks", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	links := &rgLinksResp{}
+	if err = json.Unmarshal(bytes, links); err != nil {
+		log.Println("failed to decode response for get replication group links", err)
+		return nil, err
+	}
+	status := &ReplicationStatus{
+		Bucket:           name,
+		Namespace:        namespace,
+		ReplicationGroup: info.Vpool,
+		Zones:            links.Embedded.Instances,
+		FullyReplicated:  true,
+	}
+	for _, z := range status.Zones {
+		if z.PendingSize > 0 {
+			status.FullyReplicated = false
+		}
+		if ms, err := strconv.ParseInt(z.RPOTimestamp, 10, 64); err == nil && ms > 0 {
+			z.Lag = time.Since(time.UnixMilli(ms))
+		}
+	}
+	return status, nil
+}
+
 // provides EcsBucketClient for give handler to EcsClient
-func GetEcsBucketClient(apiClient client.EcsClient) BucketClient {
-	return &bucketClient{
+func GetEcsBucketClient(apiClient client.Session, opts ...Option) BucketClient {
+	c := &bucketClient{
 		apiClient: apiClient,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
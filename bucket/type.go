@@ -2,17 +2,17 @@ package bucket
 
 import (
 	"time"
+
+	client "github.com/coredgeio/goecsclient"
 )
 
 type BucketListParameters struct {
 	// Namespace for which buckets should be listed.
 	Namespace string
 
-	// reference to last object returned.
-	Marker string
-
-	// number of objects requested in current fetch
-	Limit int
+	// reference to last object returned and number of objects requested
+	// in current fetch
+	client.ListOptions
 
 	// Case sensitive prefix of the Bucket name with a wild card(*) Ex : any_prefix_string*
 	Name string
@@ -87,14 +87,31 @@ type BucketListResp struct {
 	NextPageLink string    `json:"NextPageLink,omitempty"`
 }
 
+type namespaceListResp struct {
+	Namespaces []struct {
+		Name string `json:"name,omitempty"`
+	} `json:"namespace,omitempty"`
+	NextMarker string `json:"NextMarker,omitempty"`
+}
+
+// protocol head through which the bucket is accessed
+type HeadType string
+
+const (
+	HeadTypeS3    HeadType = "s3"
+	HeadTypeSwift HeadType = "swift"
+	HeadTypeAtmos HeadType = "atmos"
+	HeadTypeCAS   HeadType = "cas"
+)
+
 type BucketCreateReq struct {
-	BlockSize         int64  `json:"blockSize,omitempty"`
-	NotificationSize  int64  `json:"notificationSize,omitempty"`
-	Name              string `json:"name,omitempty"`
-	Vpool             string `json:"vpool,omitempty"`
-	FilesystemEnabled bool   `json:"filesystem_enabled,omitempty"`
-	HeadType          string `json:"head_type,omitempty"`
-	Namespace         string `json:"namespace,omitempty"`
+	BlockSize         int64    `json:"blockSize,omitempty"`
+	NotificationSize  int64    `json:"notificationSize,omitempty"`
+	Name              string   `json:"name,omitempty"`
+	Vpool             string   `json:"vpool,omitempty"`
+	FilesystemEnabled bool     `json:"filesystem_enabled,omitempty"`
+	HeadType          HeadType `json:"head_type,omitempty"`
+	Namespace         string   `json:"namespace,omitempty"`
 	TagSet            []struct {
 		Key   string `json:"Key,omitempty"`
 		Value string `json:"Value,omitempty"`
@@ -151,11 +168,24 @@ type BucketCreateResp struct {
 		Key   string `json:"key,omitempty"`
 		Value string `json:"value,omitempty"`
 	} `json:"TagSet,omitempty"`
+
+	// url of the created bucket as provided by Location header of the
+	// response, empty if not provided by ECS
+	Location string `json:"-"`
 }
 
+// QuotaUnlimited disables the corresponding quota limit
+const QuotaUnlimited = int64(-1)
+
+// bucket quota sizes are in GB. ECS treats the two limits independently,
+// BlockSize is the hard quota beyond which writes to the bucket are
+// blocked and NotificationSize is the soft quota beyond which ECS raises
+// a quota alert while still allowing writes. either of them can be set
+// to QuotaUnlimited (or zero) to disable it, for eg. BlockSize unlimited
+// with NotificationSize of N only warns once the bucket crosses N GB
 type BucketQuotaUpdateReq struct {
-	BlockSize        int64  `json:"blockSize,omitempty"`
-	NotificationSize int64  `json:"notificationSize,omitempty"`
+	BlockSize        int64  `json:"blockSize"`
+	NotificationSize int64  `json:"notificationSize"`
 	Namespace        string `json:"namespace,omitempty"`
 }
 
@@ -179,3 +209,326 @@ type BucketBillingInfoResp struct {
 	TotalObjectsDeleted string    `json:"total_objects_deleted,omitempty"`
 	TotalSizeDeleted    string    `json:"total_size_deleted,omitempty"`
 }
+
+// storage attributed to an object user, summed over the buckets owned by
+// the user
+type UserUsage struct {
+	UserID       string
+	Namespace    string
+	Buckets      int
+	TotalBytes   int64
+	TotalObjects int64
+}
+
+type Tag struct {
+	Key   string `json:"Key,omitempty"`
+	Value string `json:"Value,omitempty"`
+}
+
+type BucketOwnerUpdateReq struct {
+	Namespace string `json:"namespace,omitempty"`
+	NewOwner  string `json:"new_owner,omitempty"`
+}
+
+type BucketStaleAllowedUpdateReq struct {
+	Namespace      string `json:"namespace,omitempty"`
+	IsStaleAllowed bool   `json:"is_stale_allowed"`
+}
+
+// RetentionInfinite is the retention period of objects which are never
+// to be deleted, ECS encodes it as a period of -1 on the wire
+const RetentionInfinite = int64(-1)
+
+type BucketRetentionUpdateReq struct {
+	Namespace string `json:"namespace,omitempty"`
+	// retention period in seconds, RetentionInfinite for retaining
+	// objects forever and zero for no retention
+	Period int64 `json:"period"`
+}
+
+type bucketRetentionResp struct {
+	Period         int64  `json:"period"`
+	RetentionClass string `json:"retention_class,omitempty"`
+}
+
+type bucketRetentionClassUpdateReq struct {
+	Namespace      string `json:"namespace,omitempty"`
+	Period         int64  `json:"period"`
+	RetentionClass string `json:"retention_class"`
+}
+
+// retention class of namespace, a named retention period
+type RetentionClass struct {
+	Name   string `json:"name"`
+	Period int64  `json:"period"`
+}
+
+type retentionClassListResp struct {
+	RetentionClasses []RetentionClass `json:"retention_class,omitempty"`
+}
+
+type BucketTagsUpdateReq struct {
+	Namespace string `json:"namespace,omitempty"`
+	TagSet    []Tag  `json:"TagSet"`
+}
+
+// desired state of the mutable bucket settings, only the settings which
+// are set are applied on the bucket
+type BucketUpdateReq struct {
+	// Namespace the bucket belongs to
+	Namespace string
+
+	// new owner of the bucket
+	Owner string
+
+	// whether the bucket is available for reads during temporary site
+	// outage
+	IsStaleAllowed *bool
+
+	// bucket quota, namespace of the quota defaults to the request
+	// namespace
+	Quota *BucketQuotaUpdateReq
+
+	// default retention period in seconds
+	Retention *int64
+
+	// complete set of tags for the bucket
+	TagSet []Tag
+}
+
+const (
+	VersioningEnabled   = "Enabled"
+	VersioningSuspended = "Suspended"
+)
+
+type BucketVersioning struct {
+	Namespace string `json:"namespace,omitempty"`
+	// one of Enabled or Suspended, empty if versioning was never enabled
+	Status string `json:"status,omitempty"`
+}
+
+// permission of the default group on objects and directories of bucket
+type GroupPermission string
+
+const (
+	GroupFileRead    GroupPermission = "file_read"
+	GroupFileWrite   GroupPermission = "file_write"
+	GroupFileExecute GroupPermission = "file_execute"
+	GroupDirRead     GroupPermission = "dir_read"
+	GroupDirWrite    GroupPermission = "dir_write"
+	GroupDirExecute  GroupPermission = "dir_execute"
+)
+
+var allGroupPermissions = []GroupPermission{
+	GroupFileRead,
+	GroupFileWrite,
+	GroupFileExecute,
+	GroupDirRead,
+	GroupDirWrite,
+	GroupDirExecute,
+}
+
+type BucketDefaultGroupUpdateReq struct {
+	Namespace                         string `json:"namespace,omitempty"`
+	DefaultGroup                      string `json:"default_group"`
+	DefaultGroupFileReadPermission    bool   `json:"default_group_file_read_permission"`
+	DefaultGroupFileWritePermission   bool   `json:"default_group_file_write_permission"`
+	DefaultGroupFileExecutePermission bool   `json:"default_group_file_execute_permission"`
+	DefaultGroupDirReadPermission     bool   `json:"default_group_dir_read_permission"`
+	DefaultGroupDirWritePermission    bool   `json:"default_group_dir_write_permission"`
+	DefaultGroupDirExecutePermission  bool   `json:"default_group_dir_execute_permission"`
+}
+
+// rule expiring the objects of bucket after a number of days
+type LifecycleRule struct {
+	ID string `json:"id,omitempty"`
+	// objects with keys having the prefix are expired, empty for all the
+	// objects of the bucket
+	Prefix string `json:"prefix"`
+	// age of object in days after which it is expired
+	ExpirationDays int  `json:"expiration_days"`
+	Enabled        bool `json:"enabled"`
+}
+
+type BucketLifecycle struct {
+	Namespace string          `json:"namespace,omitempty"`
+	Rules     []LifecycleRule `json:"rules"`
+}
+
+const (
+	ObjectLockEnabled = "Enabled"
+
+	// objects can be deleted or retention shortened by users having
+	// bypass governance permission
+	ObjectLockModeGovernance = "GOVERNANCE"
+	// objects cannot be deleted nor retention shortened by any user
+	ObjectLockModeCompliance = "COMPLIANCE"
+)
+
+type BucketObjectLock struct {
+	Namespace         string `json:"namespace,omitempty"`
+	ObjectLockEnabled string `json:"ObjectLockEnabled,omitempty"`
+	Rule              struct {
+		DefaultRetention struct {
+			Mode string `json:"Mode,omitempty"`
+			Days int    `json:"Days,omitempty"`
+		} `json:"DefaultRetention,omitempty"`
+	} `json:"Rule,omitempty"`
+}
+
+type CORSRule struct {
+	ID string `json:"ID,omitempty"`
+	// origins allowed for cross origin requests, eg. https://app.example.com
+	// or * for any origin
+	AllowedOrigins []string `json:"AllowedOrigins"`
+	// http methods allowed for cross origin requests, eg. GET, PUT
+	AllowedMethods []string `json:"AllowedMethods"`
+	// headers allowed in preflight requests
+	AllowedHeaders []string `json:"AllowedHeaders,omitempty"`
+	// response headers accessible to the browser application
+	ExposeHeaders []string `json:"ExposeHeaders,omitempty"`
+	// time in seconds the browser can cache preflight response
+	MaxAgeSeconds int `json:"MaxAgeSeconds,omitempty"`
+}
+
+type BucketCORS struct {
+	Namespace string     `json:"namespace,omitempty"`
+	Rules     []CORSRule `json:"CORSRules"`
+}
+
+// access logging configuration of bucket, LoggingEnabled is nil when
+// logging is disabled
+type BucketLogging struct {
+	Namespace      string         `json:"namespace,omitempty"`
+	LoggingEnabled *LoggingTarget `json:"LoggingEnabled,omitempty"`
+}
+
+type LoggingTarget struct {
+	// bucket receiving the access logs
+	TargetBucket string `json:"TargetBucket"`
+	// prefix of the keys of log objects, eg. logs/
+	TargetPrefix string `json:"TargetPrefix,omitempty"`
+}
+
+// ACL permissions of bucket
+const (
+	PermissionRead            = "read"
+	PermissionWrite           = "write"
+	PermissionExecute         = "execute"
+	PermissionReadAcl         = "read_acl"
+	PermissionWriteAcl        = "write_acl"
+	PermissionFullControl     = "full_control"
+	PermissionPrivilegedWrite = "privileged_write"
+	PermissionDelete          = "delete"
+	PermissionNone            = "none"
+)
+
+// predefined groups of bucket ACL
+const (
+	// every user including anonymous ones
+	GroupPublic = "public"
+	// every authenticated user
+	GroupAllUsers = "all_users"
+)
+
+type UserAcl struct {
+	User       string   `json:"user,omitempty"`
+	Permission []string `json:"permission,omitempty"`
+}
+
+type GroupAcl struct {
+	Group      string   `json:"group,omitempty"`
+	Permission []string `json:"permission,omitempty"`
+}
+
+type BucketACL struct {
+	Bucket    string `json:"bucket,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Acl       struct {
+		Owner          string     `json:"owner,omitempty"`
+		UserAcl        []UserAcl  `json:"user_acl,omitempty"`
+		GroupAcl       []GroupAcl `json:"group_acl,omitempty"`
+		CustomGroupAcl []GroupAcl `json:"customgroup_acl,omitempty"`
+	} `json:"acl,omitempty"`
+}
+
+// types of metadata search key
+const (
+	SearchKeySystem = "System"
+	SearchKeyUser   = "User"
+)
+
+// metadata key indexed for searching the objects of bucket, Datatype is
+// required for user keys, eg. string, integer or datetime. user key names
+// carry the x-amz-meta- prefix
+type SearchKey struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Datatype string `json:"datatype,omitempty"`
+}
+
+type bucketSearchMetadataUpdateReq struct {
+	Namespace      string      `json:"namespace,omitempty"`
+	SearchMetadata []SearchKey `json:"search_metadata"`
+}
+
+// replication of the bucket to a remote zone
+type ZoneReplication struct {
+	// id and name of the replication group link to the remote zone
+	ID     string `json:"id,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Status string `json:"status,omitempty"`
+	// data written locally but yet to be replicated to the zone, in GB
+	PendingSize float64 `json:"chunksRepoPendingReplicationTotalSize,omitempty"`
+	// unix timestamp in ms till which all the data is replicated, empty
+	// if not reported
+	RPOTimestamp string `json:"replicationRpoTimestamp,omitempty"`
+	// time since RPOTimestamp, zero if not known
+	Lag time.Duration `json:"-"`
+}
+
+type ReplicationStatus struct {
+	Bucket           string
+	Namespace        string
+	ReplicationGroup string
+	Zones            []*ZoneReplication
+	// true when no data is pending replication to any of the zones
+	FullyReplicated bool
+}
+
+type rgLinksResp struct {
+	Embedded struct {
+		Instances []*ZoneReplication `json:"_instances,omitempty"`
+	} `json:"_embedded,omitempty"`
+}
+
+// desired state of a bucket compared by DiffBucket, settings left unset
+// are not compared
+type BucketSpec struct {
+	Name string
+	BucketUpdateReq
+
+	// complete set of user ACL entries for the bucket
+	UserAcl []UserAcl
+}
+
+// setting of the bucket differing from the desired one, values are
+// formatted for display
+type FieldChange struct {
+	Field   string
+	Current string
+	Desired string
+}
+
+type BucketDiff struct {
+	Changes []FieldChange
+
+	// update applying only the differing settings, can be passed to
+	// Update. ACL changes are not covered by it
+	Update BucketUpdateReq
+}
+
+// true if the bucket matches the desired spec
+func (d *BucketDiff) InSync() bool {
+	return len(d.Changes) == 0
+}
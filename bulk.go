@@ -0,0 +1,23 @@
+package goecsclient
+
+import "errors"
+
+// BulkResult is the outcome for an item of a bulk operation, eg.
+// RevokeAllSecretKeys of objectuser or PurgeNamespace of namespace. Err is
+// nil if the operation succeeded for the item
+type BulkResult[T any] struct {
+	Item T
+	Err  error
+}
+
+// joins the errors of the failed items using errors.Join, nil if all the
+// items succeeded
+func BulkError[T any](results []BulkResult[T]) error {
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -1,14 +1,140 @@
 package goecsclient
 
 import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"net/url"
+	"time"
+
+	"github.com/coredgeio/goecsclient/errors"
 )
 
-type EcsClient interface {
+// Session is the subset of EcsClient used by the resource clients
+// (bucket, namespace etc.) for making api requests. resource clients
+// only depend on this interface, so code using them can be unit tested by
+// passing a fake implementing these few methods to the resource client
+// constructors instead of a client created with CreateEcsClientWithUserCred
+type Session interface {
 	Get(subUrl string, query url.Values, h map[string]string) ([]byte, error)
 	Post(subUrl string, data []byte, query url.Values, h map[string]string) ([]byte, error)
-	Put(subUrl string, data []byte, query url.Values) ([]byte, error)
+	Put(subUrl string, data []byte, query url.Values, h map[string]string) ([]byte, error)
+	Delete(subUrl string, query url.Values, h map[string]string) ([]byte, error)
+	// same as Post for requests creating a resource, additionally
+	// providing the status and headers of the response, eg. Location
+	// header of the created resource. safe to be retried, a conflict
+	// received on a retry is assumed to be caused by the previous attempt
+	// having created the resource, whose response was lost. it fails with
+	// error matching errors.ErrCreatedByEarlierAttempt as well as
+	// errors.ErrAlreadyExists, callers can fetch the resource if needed
+	Create(subUrl string, data []byte, query url.Values, h map[string]string) (*Response, error)
+}
+
+// DataSession is the Session of clients which also reach the S3
+// compatible data api
+type DataSession interface {
+	Session
+	// endpoint of the S3 compatible data api
+	DataEndpoint() string
+	// http client used for the api requests, requests made directly
+	// using it are not authenticated
+	HTTPClient() *http.Client
+	// reads the body of a response received using HTTPClient, failing
+	// with errors.ErrResponseTooLarge beyond the limit set using
+	// WithMaxResponseBytes
+	ReadBody(r io.Reader) ([]byte, error)
+}
+
+// Lifecycle covers managing the token and connection of a client along
+// with observing its state, kept apart from the requests so that fakes of
+// Session do not need to implement it
+type Lifecycle interface {
+	// time elapsed since the current token was obtained
+	TokenAge() time.Duration
+	// time left till the current token expires, zero if expired or not
+	// known
+	TokenTTL() time.Duration
+	// version of ECS the client is talking to, cached after first fetch
+	GetVersion() (*EcsVersion, error)
+	// replaces the tls config used for api requests, preserving the
+	// token, eg. on certificate rotation
+	ReloadTLS(cfg *tls.Config) error
+	// performs login again to obtain a fresh token
+	Refresh() error
+	// repoints the client to another management endpoint, eg. on DR
+	// cutover, logging in against it. requests in flight complete against
+	// the previous endpoint
+	SetEndpoint(endpoint string) error
+	// stops the background token refresh, client must not be used after
+	// it is closed
+	Close() error
+	// same as Close, additionally rejecting new requests with
+	// ErrClientClosed and waiting for requests in flight till ctx is done
+	Shutdown(ctx context.Context) error
+}
+
+// EcsClient is the session with ECS management api on top of which all
+// the resource clients are built, providing low level escape hatches
+// and lifecycle on top of the Session.
+//
+// client created with CreateEcsClientWithUserCred is safe for concurrent
+// use by multiple goroutines and is meant to be shared, reusing its
+// connections. token refresh, whether in background or using Refresh,
+// swaps the token under a lock so requests in flight use either the old
+// or the new token. same applies to the resource clients built on it
+type EcsClient interface {
+	DataSession
+	Lifecycle
+	// same as Post, additionally providing the status and headers of the
+	// response
+	PostWithResponse(subUrl string, data []byte, query url.Values, h map[string]string) (*Response, error)
+	// low level escape hatch sending the request with auth token set and
+	// returning the raw response, body of the response must be closed by
+	// the caller
+	Do(req *http.Request) (*http.Response, error)
+	// supported escape hatch for endpoints not yet wrapped by the client,
+	// auth and errors are handled the same way as other requests while
+	// the response is returned undecoded
+	RawGet(subUrl string, query url.Values) (json.RawMessage, error)
+	RawPost(subUrl string, data []byte, query url.Values) (json.RawMessage, error)
+	// posts a multipart/form-data body, for the few endpoints which do
+	// not accept json, eg. certificate and license upload
+	PostMultipart(subUrl string, fields map[string]string, files map[string]io.Reader) ([]byte, error)
+	// provides Session whose requests are bound to ctx, eg. for a
+	// shorter deadline on metadata calls than the client wide timeout.
+	// can be passed to the resource client constructors
+	WithContext(ctx context.Context) Session
+	// establishes upto n pooled connections ahead of the first requests
+	Warmup(ctx context.Context, n int) error
+}
+
+// header scoping an api request to a namespace, supported by most of the
+// object endpoints
+const NamespaceHeader = "x-emc-namespace"
+
+// provides headers scoping the api request to given namespace, nil if
+// namespace is empty. can be extended with other headers as needed, eg.
+//
+//	h := NamespaceScope("ns1")
+//	bytes, err := apiClient.Get("/iam", query, h)
+func NamespaceScope(namespace string) map[string]string {
+	if namespace == "" {
+		return nil
+	}
+	return map[string]string{
+		NamespaceHeader: namespace,
+	}
+}
+
+// successful response of an api request
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
 }
 
 type ecsClient struct {
@@ -26,8 +152,88 @@ func (c *ecsClient) Post(subUrl string, data []byte, query url.Values, h map[str
 	return c.Session.Post(subUrl, data, query, h)
 }
 
-func (c *ecsClient) Put(subUrl string, data []byte, query url.Values) ([]byte, error) {
-	return c.Session.Put(subUrl, data, query)
+func (c *ecsClient) PostWithResponse(subUrl string, data []byte, query url.Values, h map[string]string) (*Response, error) {
+	return c.Session.PostWithResponse(subUrl, data, query, h)
+}
+
+func (c *ecsClient) Create(subUrl string, data []byte, query url.Values, h map[string]string) (*Response, error) {
+	return c.Session.Create(subUrl, data, query, h)
+}
+
+func (c *ecsClient) PostMultipart(subUrl string, fields map[string]string, files map[string]io.Reader) ([]byte, error) {
+	return c.Session.PostMultipart(subUrl, fields, files)
+}
+
+func (c *ecsClient) Delete(subUrl string, query url.Values, h map[string]string) ([]byte, error) {
+	return c.Session.Delete(subUrl, query, h)
+}
+
+func (c *ecsClient) Put(subUrl string, data []byte, query url.Values, h map[string]string) ([]byte, error) {
+	return c.Session.Put(subUrl, data, query, h)
+}
+
+func (c *ecsClient) Do(req *http.Request) (*http.Response, error) {
+	return c.Session.Do(req)
+}
+
+func (c *ecsClient) RawGet(subUrl string, query url.Values) (json.RawMessage, error) {
+	return c.Session.RawGet(subUrl, query)
+}
+
+func (c *ecsClient) RawPost(subUrl string, data []byte, query url.Values) (json.RawMessage, error) {
+	return c.Session.RawPost(subUrl, data, query)
+}
+
+func (c *ecsClient) WithContext(ctx context.Context) Session {
+	return c.Session.WithContext(ctx)
+}
+
+func (c *ecsClient) Warmup(ctx context.Context, n int) error {
+	return c.Session.Warmup(ctx, n)
+}
+
+func (c *ecsClient) DataEndpoint() string {
+	return c.Session.DataEndpoint()
+}
+
+func (c *ecsClient) HTTPClient() *http.Client {
+	return c.Session.HTTPClient()
+}
+
+func (c *ecsClient) ReadBody(r io.Reader) ([]byte, error) {
+	return c.Session.ReadBody(r)
+}
+
+func (c *ecsClient) TokenAge() time.Duration {
+	return c.Session.TokenAge()
+}
+
+func (c *ecsClient) TokenTTL() time.Duration {
+	return c.Session.TokenTTL()
+}
+
+func (c *ecsClient) GetVersion() (*EcsVersion, error) {
+	return c.Session.GetVersion()
+}
+
+func (c *ecsClient) ReloadTLS(cfg *tls.Config) error {
+	return c.Session.ReloadTLS(cfg)
+}
+
+func (c *ecsClient) Refresh() error {
+	return c.Session.Refresh()
+}
+
+func (c *ecsClient) SetEndpoint(endpoint string) error {
+	return c.Session.SetEndpoint(endpoint)
+}
+
+func (c *ecsClient) Close() error {
+	return c.Session.Close()
+}
+
+func (c *ecsClient) Shutdown(ctx context.Context) error {
+	return c.Session.Shutdown(ctx)
 }
 
 // creates Ecs management API client using username and password of provided
@@ -36,11 +242,13 @@ func (c *ecsClient) Put(subUrl string, data []byte, query url.Values) ([]byte, e
 // Additionally it also requires api endpoint for the dell ecs management API
 // server
 //
+// optional behaviour of the client can be configured by passing options
+//
 // Upon successful creation of client it returns the client handle.
 // whereas, if the creation fails the handle will be nil and corresponding
 // error is returned
-func CreateEcsClientWithUserCred(username, password, endpoint string) (EcsClient, error) {
-	session, err := createEcsSession(username, password, endpoint)
+func CreateEcsClientWithUserCred(username, password, endpoint string, opts ...Option) (EcsClient, error) {
+	session, err := createEcsSession(username, password, endpoint, opts...)
 	if err != nil {
 		log.Println("ecs client failed to create ecs session", err)
 		return nil, err
@@ -48,9 +256,66 @@ func CreateEcsClientWithUserCred(username, password, endpoint string) (EcsClient
 	cl := &ecsClient{
 		Username: username,
 		Password: password,
-		Endpoint: endpoint,
+		Endpoint: session.Endpoint,
 		Session:  session,
 	}
 
 	return cl, nil
 }
+
+// checks that ECS management api is reachable at the endpoint without
+// needing credentials, eg. for validating connectivity in a setup wizard
+// separately from the credentials, which can be checked using
+// ValidateCredentials. options are applied the same as while creating the
+// client, eg. proxy or tls settings.
+//
+// returns nil if ECS responded, error matching
+// errors.ErrEndpointUnreachable wrapping the cause if the endpoint could
+// not be reached due to dns, connection or tls failure, or the error
+// received for an unexpected response, eg. a proxy in between failing
+func CheckEndpoint(ctx context.Context, endpoint string, opts ...Option) error {
+	session, err := newEcsSession("", "", endpoint, opts...)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	req, err := http.NewRequestWithContext(ctx, "GET", session.Endpoint+"/login", nil)
+	if err != nil {
+		return err
+	}
+	session.setCommonHeaders(req)
+	resp, err := session.c.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w: %w", errors.ErrEndpointUnreachable, err)
+	}
+	resp.Body.Close()
+	// login without credentials is expected to be rejected
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode < http.StatusMultipleChoices {
+		return nil
+	}
+	return errors.ParseHttpError(resp.StatusCode, resp.Status, nil)
+}
+
+// validates the credentials by performing a login followed by logout,
+// without starting the token refresh or retaining any state.
+//
+// returns nil if the credentials are valid, error matching
+// errors.ErrUnauthorized if ECS rejected the credentials, or the error
+// encountered while reaching the endpoint
+func ValidateCredentials(username, password, endpoint string, opts ...Option) error {
+	opts = append(opts, WithAutoRefresh(false))
+	session, err := createEcsSession(username, password, endpoint, opts...)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	if err = session.logout(); err != nil {
+		// credentials are already validated by login, the token will
+		// anyway expire on its own
+		log.Println("failed to logout after validating credentials", err)
+	}
+	return nil
+}
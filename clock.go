@@ -0,0 +1,24 @@
+package goecsclient
+
+import "time"
+
+// Clock provides the time used for scheduling token refresh and tracking
+// the token age, allowing tests to drive the refresh with a fake clock
+// instead of real waits
+type Clock interface {
+	Now() time.Time
+	// same as time.After
+	After(d time.Duration) <-chan time.Time
+}
+
+// Clock backed by the time package, used unless overridden using
+// WithClock
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
@@ -0,0 +1,114 @@
+package goecsclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// clock advanced only by the test, signalling every wait started on it
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+	waiting chan struct{}
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		waiting: make(chan struct{}, 16),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	c.waiting <- struct{}{}
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = pending
+}
+
+// waits for the refresh loop to start waiting on the clock
+func (c *fakeClock) awaitWaiter(t *testing.T) {
+	t.Helper()
+	select {
+	case <-c.waiting:
+	case <-time.After(5 * time.Second):
+		t.Fatal("refresh did not wait on the clock")
+	}
+}
+
+func TestRefreshWithFakeClock(t *testing.T) {
+	var logins int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			atomic.AddInt32(&logins, 1)
+			w.Header().Set(DefaultTokenHeader, testToken)
+			w.Header().Set("X-SDS-AUTH-MAX-AGE", "3600")
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	clock := newFakeClock()
+	c, err := CreateEcsClientWithUserCred("user", "pass", srv.URL, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	clock.awaitWaiter(t)
+
+	if ttl := c.TokenTTL(); ttl != time.Hour {
+		t.Fatalf("expected ttl of an hour, got %v", ttl)
+	}
+	// refresh is due TimeBufferInSeconds before the token expires
+	due := time.Duration(3600-TimeBufferInSeconds) * time.Second
+	clock.Advance(due - time.Second)
+	if got := atomic.LoadInt32(&logins); got != 1 {
+		t.Fatalf("expected no refresh before due, got %d logins", got)
+	}
+	if age := c.TokenAge(); age != due-time.Second {
+		t.Fatalf("expected token age %v, got %v", due-time.Second, age)
+	}
+
+	clock.Advance(time.Second)
+	// loop waits again once the token is refreshed
+	clock.awaitWaiter(t)
+	if got := atomic.LoadInt32(&logins); got != 2 {
+		t.Fatalf("expected token to be refreshed once, got %d logins", got)
+	}
+	if age := c.TokenAge(); age != 0 {
+		t.Fatalf("expected fresh token, got age %v", age)
+	}
+}
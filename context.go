@@ -0,0 +1,66 @@
+package goecsclient
+
+import (
+	"context"
+	"net/url"
+)
+
+// session sharing the token and connections of ecsSession, with the
+// requests bound to ctx
+type ctxSession struct {
+	s   *ecsSession
+	ctx context.Context
+}
+
+// provides Session whose requests are cancelled once ctx is done. the
+// client wide timeout set using WithTimeout still applies, the request
+// fails on whichever is earlier. retries are not attempted once ctx is
+// done
+func (s *ecsSession) WithContext(ctx context.Context) Session {
+	return &ctxSession{
+		s:   s,
+		ctx: ctx,
+	}
+}
+
+func (c *ctxSession) Get(subUrl string, q url.Values, headers map[string]string) ([]byte, error) {
+	resp, err := c.s.doRequest(c.ctx, "GET", subUrl, nil, q, headers, false)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *ctxSession) Post(subUrl string, d []byte, q url.Values, headers map[string]string) ([]byte, error) {
+	resp, err := c.s.doRequest(c.ctx, "POST", subUrl, d, q, headers, false)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *ctxSession) Put(subUrl string, d []byte, q url.Values, headers map[string]string) ([]byte, error) {
+	resp, err := c.s.doRequest(c.ctx, "PUT", subUrl, d, q, headers, false)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *ctxSession) Delete(subUrl string, q url.Values, headers map[string]string) ([]byte, error) {
+	resp, err := c.s.doRequest(c.ctx, "DELETE", subUrl, nil, q, headers, false)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *ctxSession) Create(subUrl string, d []byte, q url.Values, headers map[string]string) (*Response, error) {
+	return c.s.doRequest(c.ctx, "POST", subUrl, d, q, headers, true)
+}
+
+// version is shared with the parent session, fetched without ctx since it
+// gets cached for the lifetime of the session
+func (c *ctxSession) GetVersion() (*EcsVersion, error) {
+	return c.s.GetVersion()
+}
@@ -0,0 +1,151 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"log"
+
+	client "github.com/coredgeio/goecsclient"
+)
+
+// DashboardClient provides the monitoring data of the local zone, same
+// as the one shown on ECS portal
+type DashboardClient interface {
+	GetStoragePools() (*DashboardStoragePools, error)
+	GetNodes() (*DashboardNodes, error)
+	GetStoragePoolNodes(id string) (*DashboardNodes, error)
+	GetVDCs() (*VDCListResp, error)
+	GetTopology() (*Topology, error)
+	ListRecoveryTasks() (*RecoveryTaskList, error)
+	GetRecoveryTask(id string) (*RecoveryTask, error)
+}
+
+type dashboardClient struct {
+	apiClient client.Session
+}
+
+func (c *dashboardClient) GetStoragePools() (*DashboardStoragePools, error) {
+	bytes, err := c.apiClient.Get("/dashboard/zones/localzone/storagepools", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &DashboardStoragePools{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get dashboard storage pools", err)
+	}
+	return resp, err
+}
+
+func (c *dashboardClient) GetNodes() (*DashboardNodes, error) {
+	bytes, err := c.apiClient.Get("/dashboard/zones/localzone/nodes", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &DashboardNodes{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get dashboard nodes", err)
+	}
+	return resp, err
+}
+
+func (c *dashboardClient) GetStoragePoolNodes(id string) (*DashboardNodes, error) {
+	bytes, err := c.apiClient.Get("/dashboard/storagepools/"+id+"/nodes", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &DashboardNodes{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get dashboard storage pool nodes", err)
+	}
+	return resp, err
+}
+
+func (c *dashboardClient) GetVDCs() (*VDCListResp, error) {
+	bytes, err := c.apiClient.Get("/object/vdcs/vdc/list", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &VDCListResp{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get vdc list", err)
+	}
+	return resp, err
+}
+
+// provides VDCs with their storage pools and nodes of each pool in one
+// tree, for inventory views.
+//
+// dashboard serves storage pools of the local zone only, so storage pools
+// are filled only for the local VDC, other VDCs of a geo federation are
+// listed without them. this costs a request per storage pool on top of
+// listing VDCs and storage pools
+func (c *dashboardClient) GetTopology() (*Topology, error) {
+	vdcs, err := c.GetVDCs()
+	if err != nil {
+		return nil, err
+	}
+	pools, err := c.GetStoragePools()
+	if err != nil {
+		return nil, err
+	}
+	var localPools []*TopologyStoragePool
+	for _, pool := range pools.Embedded.Instances {
+		nodes, err := c.GetStoragePoolNodes(pool.ID)
+		if err != nil {
+			return nil, err
+		}
+		localPools = append(localPools, &TopologyStoragePool{
+			StoragePool: pool,
+			Nodes:       nodes.Embedded.Instances,
+		})
+	}
+	topology := &Topology{}
+	for _, vdc := range vdcs.VDCs {
+		t := &TopologyVDC{VDC: vdc}
+		if vdc.Local {
+			t.StoragePools = localPools
+		}
+		topology.VDCs = append(topology.VDCs, t)
+	}
+	return topology, nil
+}
+
+// lists the recovery tasks of the local zone, eg. data rebuild after node
+// replacement, including the completed ones still tracked by ECS
+func (c *dashboardClient) ListRecoveryTasks() (*RecoveryTaskList, error) {
+	bytes, err := c.apiClient.Get("/vdc/recovery/tasks", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &RecoveryTaskList{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for list recovery tasks", err)
+	}
+	return resp, err
+}
+
+// provides progress of a single recovery task, fails with ErrNotFound
+// once the task is no longer tracked
+func (c *dashboardClient) GetRecoveryTask(id string) (*RecoveryTask, error) {
+	bytes, err := c.apiClient.Get("/vdc/recovery/tasks/"+id, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &RecoveryTask{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get recovery task", err)
+	}
+	return resp, err
+}
+
+// provides EcsDashboardClient for give handler to EcsClient
+func GetEcsDashboardClient(apiClient client.Session) DashboardClient {
+	return &dashboardClient{
+		apiClient: apiClient,
+	}
+}
@@ -0,0 +1,105 @@
+package dashboard
+
+// sample of space metric, space is in GB and t is the unix timestamp of
+// the sample
+type SpaceSample struct {
+	Space float64 `json:"Space,omitempty"`
+	T     string  `json:"t,omitempty"`
+}
+
+// sample of utilization metric, t is the unix timestamp of the sample
+type PercentSample struct {
+	Percent float64 `json:"Percent,omitempty"`
+	T       string  `json:"t,omitempty"`
+}
+
+type StoragePool struct {
+	ID                        string        `json:"id,omitempty"`
+	Name                      string        `json:"name,omitempty"`
+	NumNodes                  int           `json:"numNodes,omitempty"`
+	NumGoodNodes              int           `json:"numGoodNodes,omitempty"`
+	NumBadNodes               int           `json:"numBadNodes,omitempty"`
+	NumMaintenanceNodes       int           `json:"numMaintenanceNodes,omitempty"`
+	NumDisks                  int           `json:"numDisks,omitempty"`
+	NumGoodDisks              int           `json:"numGoodDisks,omitempty"`
+	NumBadDisks               int           `json:"numBadDisks,omitempty"`
+	DiskSpaceTotalCurrent     []SpaceSample `json:"diskSpaceTotalCurrent,omitempty"`
+	DiskSpaceFreeCurrent      []SpaceSample `json:"diskSpaceFreeCurrent,omitempty"`
+	DiskSpaceAllocatedCurrent []SpaceSample `json:"diskSpaceAllocatedCurrent,omitempty"`
+}
+
+type DashboardStoragePools struct {
+	Embedded struct {
+		Instances []*StoragePool `json:"_instances,omitempty"`
+	} `json:"_embedded,omitempty"`
+}
+
+type Node struct {
+	ID                           string          `json:"id,omitempty"`
+	DisplayName                  string          `json:"displayName,omitempty"`
+	NumDisks                     int             `json:"numDisks,omitempty"`
+	NumGoodDisks                 int             `json:"numGoodDisks,omitempty"`
+	NumBadDisks                  int             `json:"numBadDisks,omitempty"`
+	NumMaintenanceDisks          int             `json:"numMaintenanceDisks,omitempty"`
+	DiskSpaceTotalCurrent        []SpaceSample   `json:"diskSpaceTotalCurrent,omitempty"`
+	DiskSpaceFreeCurrent         []SpaceSample   `json:"diskSpaceFreeCurrent,omitempty"`
+	DiskSpaceAllocatedCurrent    []SpaceSample   `json:"diskSpaceAllocatedCurrent,omitempty"`
+	NodeCpuUtilizationCurrent    []PercentSample `json:"nodeCpuUtilizationCurrent,omitempty"`
+	NodeMemoryUtilizationCurrent []PercentSample `json:"nodeMemoryUtilizationCurrent,omitempty"`
+	NodeNicUtilizationCurrent    []PercentSample `json:"nodeNicUtilizationCurrent,omitempty"`
+	HealthStatus                 string          `json:"healthStatus,omitempty"`
+}
+
+type DashboardNodes struct {
+	Embedded struct {
+		Instances []*Node `json:"_instances,omitempty"`
+	} `json:"_embedded,omitempty"`
+}
+
+type VDC struct {
+	ID      string `json:"id,omitempty"`
+	VdcID   string `json:"vdcId,omitempty"`
+	VdcName string `json:"vdcName,omitempty"`
+	// set for the VDC the client is connected to
+	Local bool `json:"local,omitempty"`
+}
+
+type VDCListResp struct {
+	VDCs []*VDC `json:"vdc,omitempty"`
+}
+
+// nodes, storage pools and VDCs correlated into a tree
+type Topology struct {
+	VDCs []*TopologyVDC
+}
+
+type TopologyVDC struct {
+	*VDC
+	// storage pools of the VDC, known only for the local VDC
+	StoragePools []*TopologyStoragePool
+}
+
+type TopologyStoragePool struct {
+	*StoragePool
+	Nodes []*Node
+}
+
+// states of recovery task
+const (
+	RecoveryTaskPending    = "PENDING"
+	RecoveryTaskInProgress = "IN_PROGRESS"
+	RecoveryTaskCompleted  = "COMPLETED"
+	RecoveryTaskFailed     = "FAILED"
+)
+
+// task recovering data after failure or replacement of a node or disk
+type RecoveryTask struct {
+	ID              string  `json:"id,omitempty"`
+	Type            string  `json:"type,omitempty"`
+	PercentComplete float64 `json:"percent_complete,omitempty"`
+	State           string  `json:"state,omitempty"`
+}
+
+type RecoveryTaskList struct {
+	Tasks []*RecoveryTask `json:"recovery_task,omitempty"`
+}
@@ -0,0 +1,27 @@
+package goecsclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+const redactedValue = "<redacted>"
+
+// provides copy of the request which is safe to share with dry run
+// callback, credentials in headers and body are redacted and body is readable independent of
+// the original request. tokenHeader is the header carrying auth token
+func previewRequest(req *http.Request, d []byte, tokenHeader string) *http.Request {
+	preview := req.Clone(context.Background())
+	preview.Header = redactHeader(req.Header, tokenHeader)
+	if d != nil {
+		d = redactBody(d)
+		preview.ContentLength = int64(len(d))
+		preview.Body = io.NopCloser(bytes.NewReader(d))
+		preview.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(d)), nil
+		}
+	}
+	return preview
+}
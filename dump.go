@@ -0,0 +1,71 @@
+package goecsclient
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"sync"
+)
+
+// writes wire dump of requests and responses of a session, credentials
+// are redacted before writing
+type debugDumper struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (d *debugDumper) write(dump []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, err := d.w.Write(append(dump, '\n')); err != nil {
+		log.Println("failed to write debug dump", err)
+	}
+}
+
+// dumps the request along with its body, which is passed separately
+// since the body of request is consumed while sending it
+func (d *debugDumper) dumpRequest(req *http.Request, body []byte, tokenHeader string) {
+	dump, err := httputil.DumpRequestOut(previewRequest(req, body, tokenHeader), true)
+	if err != nil {
+		log.Println("failed to dump request", err)
+		return
+	}
+	d.write(dump)
+}
+
+// dumps the response along with its body, body of the response is
+// restored after reading so it remains readable for the caller.
+// credentials in the body, eg. secret keys, are redacted the same as of
+// request bodies. at most maxBytes of the body are read for the dump,
+// larger bodies are left out of it since a truncated body cannot be
+// redacted
+func (d *debugDumper) dumpResponse(resp *http.Response, tokenHeader string, maxBytes int64) {
+	var body []byte
+	if resp.Body != nil && resp.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		if err != nil {
+			log.Println("failed to read response for dump", err)
+			return
+		}
+	}
+	redacted := *resp
+	redacted.Header = redactHeader(resp.Header, tokenHeader)
+	dump, err := httputil.DumpResponse(&redacted, false)
+	if err != nil {
+		log.Println("failed to dump response", err)
+		return
+	}
+	if int64(len(body)) > maxBytes {
+		d.write(append(dump, "[body larger than "+strconv.FormatInt(maxBytes, 10)+" bytes not dumped]"...))
+		return
+	}
+	d.write(append(dump, redactBody(body)...))
+}
@@ -0,0 +1,61 @@
+package encryption
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	client "github.com/coredgeio/goecsclient"
+)
+
+// EncryptionClient reports the data at rest encryption of the cluster,
+// for compliance reporting. encryption of individual namespaces and
+// buckets is reported by their own clients
+type EncryptionClient interface {
+	GetEncryptionStatus() (*EncryptionStatus, error)
+}
+
+type encryptionClient struct {
+	apiClient client.Session
+}
+
+// provides whether data at rest encryption is enabled along with the
+// status of the latest key rotation, requires system admin or system
+// monitor privileges
+func (c *encryptionClient) GetEncryptionStatus() (*EncryptionStatus, error) {
+	bytes, err := c.apiClient.Get("/vdc/keymanagement/encryption", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	enc := &encryptionResp{}
+	if err = json.Unmarshal(bytes, enc); err != nil {
+		log.Println("failed to decode response for get encryption", err)
+		return nil, err
+	}
+	status := &EncryptionStatus{Enabled: enc.Enabled}
+	if !enc.Enabled {
+		return status, nil
+	}
+
+	bytes, err = c.apiClient.Get("/vdc/keymanagement/rotation/status", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	rotation := &rotationStatusResp{}
+	if err = json.Unmarshal(bytes, rotation); err != nil {
+		log.Println("failed to decode response for get key rotation status", err)
+		return nil, err
+	}
+	status.RotationStatus = rotation.Status
+	if rotation.StartTime > 0 {
+		status.LastRotation = time.UnixMilli(rotation.StartTime)
+	}
+	return status, nil
+}
+
+// provides EcsEncryptionClient for given handler to EcsClient
+func GetEcsEncryptionClient(apiClient client.Session) EncryptionClient {
+	return &encryptionClient{
+		apiClient: apiClient,
+	}
+}
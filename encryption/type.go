@@ -0,0 +1,31 @@
+package encryption
+
+import "time"
+
+// states of key rotation
+const (
+	RotationCompleted  = "COMPLETED"
+	RotationInProgress = "IN_PROGRESS"
+	RotationFailed     = "FAILED"
+)
+
+// data at rest encryption state of the system
+type EncryptionStatus struct {
+	// whether data at rest encryption is enabled for the cluster
+	Enabled bool
+	// state of the latest key rotation, empty if keys were never rotated
+	RotationStatus string
+	// time the latest key rotation was started, zero if keys were never
+	// rotated
+	LastRotation time.Time
+}
+
+type encryptionResp struct {
+	Enabled bool `json:"is_encryption_enabled"`
+}
+
+type rotationStatusResp struct {
+	Status string `json:"status,omitempty"`
+	// unix timestamp in ms
+	StartTime int64 `json:"start_time,omitempty"`
+}
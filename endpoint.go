@@ -0,0 +1,109 @@
+package goecsclient
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coredgeio/goecsclient/errors"
+)
+
+const (
+	// default port of the management api over https
+	DefaultManagementPort = "4443"
+	// default port of the S3 compatible data api over https
+	DefaultDataPort = "9021"
+)
+
+// derives the S3 data endpoint from management endpoint, data api is
+// served by the same nodes on the given port
+func deriveDataEndpoint(endpoint, port string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return "https://" + net.JoinHostPort(u.Hostname(), port)
+}
+
+// replaces the port of endpoint, keeping its scheme and path
+func endpointWithPort(endpoint, port string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", errors.Wrap("endpoint " + endpoint + " does not have a host")
+	}
+	u.Host = net.JoinHostPort(u.Hostname(), port)
+	return trimEndpoint(u.String()), nil
+}
+
+// validates the port is in range, providing it as string
+func validatePort(port int) (string, error) {
+	if port < 1 || port > 65535 {
+		return "", errors.Wrap("port " + strconv.Itoa(port) + " out of range 1-65535")
+	}
+	return strconv.Itoa(port), nil
+}
+
+func trimEndpoint(endpoint string) string {
+	return strings.TrimRight(endpoint, "/")
+}
+
+// validates the endpoint is an http or https url with a host
+func validateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return errors.Wrap("endpoint " + endpoint + " must be an http or https url")
+	}
+	if u.Host == "" {
+		return errors.Wrap("endpoint " + endpoint + " does not have a host")
+	}
+	return nil
+}
+
+// repoints the session to another management endpoint, eg. when the VIP
+// of ECS changes on DR cutover. login is performed against the new
+// endpoint first and the session is left unchanged if it fails, otherwise
+// the endpoint and token are swapped together so that a request uses
+// either the old endpoint and token or the new ones. requests in flight
+// complete against the old endpoint. port set using WithManagementPort
+// applies to the new endpoint, same for the data endpoint unless it was
+// set using WithDataEndpoint. background refresh if enabled is
+// rescheduled as per the age of the new token
+func (s *ecsSession) SetEndpoint(endpoint string) error {
+	endpoint = trimEndpoint(endpoint)
+	if err := validateEndpoint(endpoint); err != nil {
+		return err
+	}
+	if s.mgmtPort != "" {
+		ep, err := endpointWithPort(endpoint, s.mgmtPort)
+		if err != nil {
+			return err
+		}
+		endpoint = ep
+	}
+
+	s.loginMu.Lock()
+	defer s.loginMu.Unlock()
+	token, age, err := s.login(endpoint)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.Endpoint = endpoint
+	if s.dataEndpointDerived {
+		s.dataEndpoint = deriveDataEndpoint(endpoint, s.dataPort)
+	}
+	s.Token = token
+	s.tokenIssuedAt = s.clock.Now()
+	s.tokenMaxAge = time.Duration(age) * time.Second
+	s.mu.Unlock()
+	s.rescheduleRefresh(age)
+	return nil
+}
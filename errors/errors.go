@@ -2,24 +2,77 @@ package errors
 
 import (
 	"encoding/json"
+	stderrors "errors"
+	"net/http"
 )
 
 type ErrCode int
 
+// error codes reported by ECS, unlike the error messages these remain
+// stable across ECS versions and should be preferred for matching
 const (
 	Unknown ErrCode = 0
+
+	ErrCodeObjectExists        ErrCode = 1004
+	ErrCodeInvalidParameter    ErrCode = 1008
+	ErrCodeBadRequestBody      ErrCode = 1013
+	ErrCodeBucketAlreadyExists ErrCode = 40008
+)
+
+// sentinel errors to be used with errors.Is, an *Error received from ECS
+// matches these based on the http status and ECS error code. for example
+// if errors.Is(err, errors.ErrNotFound) { ... }
+var (
+	ErrNotFound      = &Error{Msg: "resource not found"}
+	ErrAlreadyExists = &Error{Msg: "resource already exists"}
+	ErrUnauthorized  = &Error{Msg: "unauthorized"}
+	// current state of the resource did not match the expected state of
+	// a conditional update
+	ErrPreconditionFailed = &Error{Msg: "precondition failed"}
+)
+
+// errors generated by the client itself, without reaching ECS
+var (
+	ErrReplicationGroupNotFound = &Error{Msg: "replication group not found"}
+	// raw request made using Do in preview only mode, which cannot be
+	// answered with an empty result
+	ErrPreviewOnly = &Error{Msg: "request not sent, client is in preview only mode"}
+	// request made after the client was shut down
+	ErrClientClosed = &Error{Msg: "client is shut down"}
+	// permissions granted through custom groups could not be resolved
+	// since group membership of the user is not known
+	ErrCustomGroupUnresolved = &Error{Msg: "custom group membership not resolved"}
+	// operation is not available on the version of ECS being talked to
+	ErrUnsupported = &Error{Msg: "operation not supported by ECS version"}
+	// endpoint could not be reached, eg. dns, connection or tls failure.
+	// original error is wrapped along with it
+	ErrEndpointUnreachable = &Error{Msg: "endpoint unreachable"}
+	// response body exceeded the limit set using WithMaxResponseBytes
+	ErrResponseTooLarge = &Error{Msg: "response body too large"}
+	// retried create was rejected with a conflict, the resource was most
+	// likely created by an earlier attempt whose response got lost. the
+	// conflict received is wrapped along with it, hence it matches
+	// ErrAlreadyExists as well
+	ErrCreatedByEarlierAttempt = &Error{Msg: "resource likely created by an earlier attempt"}
 )
 
 // get the error code if the error is
 // associated to recognizable error types
 func getErrCode(err error) ErrCode {
-	val, ok := err.(*Error)
-	if ok {
-		return ErrCode(val.Code)
+	var val *Error
+	if stderrors.As(err, &val) {
+		return val.Code
 	}
 	return Unknown
 }
 
+// provides the ECS error code carried by the error, returns false if the
+// error does not carry an ECS error code
+func ErrorCode(err error) (int, bool) {
+	code := getErrCode(err)
+	return int(code), code != Unknown
+}
+
 // base error structure, following is the sample response
 // {"code":40008,"description":"\"Bucket already exists\"","details":"Bucket already exists","retryable":false}
 type Error struct {
@@ -27,6 +80,10 @@ type Error struct {
 	Desc      string  `json:"description,omitempty"`
 	Msg       string  `json:"details,omitempty"`
 	Retryable bool    `json:"retryable,omitempty"`
+
+	// http status code of the response carrying the error, zero if the
+	// error was not generated from an api response
+	StatusCode int `json:"-"`
 }
 
 // Error() prints out the error message string
@@ -34,6 +91,22 @@ func (e Error) Error() string {
 	return e.Msg
 }
 
+// Is allows matching against the sentinel errors using errors.Is
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrAlreadyExists:
+		return e.StatusCode == http.StatusConflict || e.Code == ErrCodeObjectExists ||
+			e.Code == ErrCodeBucketAlreadyExists
+	case ErrPreconditionFailed:
+		return e.StatusCode == http.StatusPreconditionFailed
+	}
+	return false
+}
+
 // Wraps the error msg
 func Wrap(msg string) error {
 	return &Error{
@@ -52,3 +125,17 @@ func ParseError(data []byte) error {
 
 	return e
 }
+
+// parses the error response body along with the http status of the
+// response, status text is used as error message when body is empty
+func ParseHttpError(statusCode int, status string, data []byte) error {
+	if len(data) == 0 {
+		return &Error{
+			Msg:        status,
+			StatusCode: statusCode,
+		}
+	}
+	e := ParseError(data).(*Error)
+	e.StatusCode = statusCode
+	return e
+}
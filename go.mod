@@ -1,3 +1,5 @@
 module github.com/coredgeio/goecsclient
 
 go 1.22
+
+require golang.org/x/net v0.25.0
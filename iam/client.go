@@ -30,7 +30,7 @@ type IamClient interface {
 }
 
 type iamClient struct {
-	apiClient client.EcsClient
+	apiClient client.Session
 }
 
 // Create Policy
@@ -56,16 +56,14 @@ func (c *iamClient) CreatePolicy(namespace string, param *CreatePolicyParameters
 		}
 	}
 
-	h := map[string]string{
-		"x-emc-namespace": namespace,
-	}
-	bytes, err := c.apiClient.Post("/iam", nil, query, h)
+	h := client.NamespaceScope(namespace)
+	r, err := c.apiClient.Create("/iam", nil, query, h)
 	if err != nil {
 		return nil, err
 	}
 
 	resp := &CreatePolicyResp{}
-	if err = json.Unmarshal(bytes, resp); err != nil {
+	if err = json.Unmarshal(r.Body, resp); err != nil {
 		log.Println("failed to decode response for Create Policy", err)
 	}
 	return resp, err
@@ -84,9 +82,7 @@ func (c *iamClient) GetPolicy(namespace string, param *GetPolicyParameters) (*Ge
 		}
 	}
 
-	h := map[string]string{
-		"x-emc-namespace": namespace,
-	}
+	h := client.NamespaceScope(namespace)
 	bytes, err := c.apiClient.Post("/iam", nil, query, h)
 	if err != nil {
 		return nil, err
@@ -102,34 +98,29 @@ func (c *iamClient) GetPolicy(namespace string, param *GetPolicyParameters) (*Ge
 // List Policies
 func (c *iamClient) ListPolicies(namespace string, param *ListPoliciesParameters) (*ListPoliciesResp, error) {
 	var query url.Values
-	if param != nil && (param.Marker != "" || param.MaxItems != 0 || param.OnlyAttached != "" || param.PathPrefix != "" ||
-		param.PolicyScope != "" || param.PolicyUsageFilter != "") || param.Action == "ListPolicies" {
-		query = url.Values{}
-		if param.Marker != "" {
-			query.Add("Marker", param.Marker)
-		}
-		if param.OnlyAttached != "" {
-			query.Add("OnlyAttached", param.OnlyAttached)
-		}
-		if param.PathPrefix != "" {
-			query.Add("PathPrefix", param.PathPrefix)
-		}
-		if param.PolicyScope != "" {
-			query.Add("PolicyScope", param.PolicyScope)
-		}
-		if param.PolicyUsageFilter != "" {
-			query.Add("PolicyUsageFilter", param.PolicyUsageFilter)
-		}
-		if param.MaxItems != 0 {
-			query.Add("MaxItems", strconv.Itoa(param.MaxItems))
-		}
-		if param.Action != "" {
-			query.Add("Action", param.Action)
-		}
-	}
-	h := map[string]string{
-		"x-emc-namespace": namespace,
-	}
+	if param != nil {
+		if param.OnlyAttached != "" || param.PathPrefix != "" || param.PolicyScope != "" ||
+			param.PolicyUsageFilter != "" || param.Action != "" {
+			query = url.Values{}
+			if param.OnlyAttached != "" {
+				query.Add("OnlyAttached", param.OnlyAttached)
+			}
+			if param.PathPrefix != "" {
+				query.Add("PathPrefix", param.PathPrefix)
+			}
+			if param.PolicyScope != "" {
+				query.Add("PolicyScope", param.PolicyScope)
+			}
+			if param.PolicyUsageFilter != "" {
+				query.Add("PolicyUsageFilter", param.PolicyUsageFilter)
+			}
+			if param.Action != "" {
+				query.Add("Action", param.Action)
+			}
+		}
+		query = param.AddToQuery(query, client.IAMPageParams)
+	}
+	h := client.NamespaceScope(namespace)
 	bytes, err := c.apiClient.Post("/iam", nil, query, h)
 	if err != nil {
 		return nil, err
@@ -153,9 +144,7 @@ func (c *iamClient) DeletePolicy(namespace string, param *DeletePolicyParameters
 		query.Add("Action", param.Action)
 	}
 
-	h := map[string]string{
-		"x-emc-namespace": namespace,
-	}
+	h := client.NamespaceScope(namespace)
 	bytes, err := c.apiClient.Post("/iam", nil, query, h)
 	if err != nil {
 		return nil, err
@@ -186,9 +175,7 @@ func (c *iamClient) CreatePolicyVersion(namespace string, param *CreatePolicyVer
 	}
 	query.Add("SetAsDefault", strconv.FormatBool(param.SetAsDefault))
 
-	h := map[string]string{
-		"x-emc-namespace": namespace,
-	}
+	h := client.NamespaceScope(namespace)
 	bytes, err := c.apiClient.Post("/iam", nil, query, h)
 	if err != nil {
 		return nil, err
@@ -217,9 +204,7 @@ func (c *iamClient) DeletePolicyVersion(namespace string, param *DeletePolicyVer
 		}
 	}
 
-	h := map[string]string{
-		"x-emc-namespace": namespace,
-	}
+	h := client.NamespaceScope(namespace)
 	bytes, err := c.apiClient.Post("/iam", nil, query, h)
 	if err != nil {
 		return nil, err
@@ -248,9 +233,7 @@ func (c *iamClient) AttachPolicy(namespace string, param *AttachPolicyParameters
 		}
 	}
 
-	h := map[string]string{
-		"x-emc-namespace": namespace,
-	}
+	h := client.NamespaceScope(namespace)
 	bytes, err := c.apiClient.Post("/iam", nil, query, h)
 	if err != nil {
 		return nil, err
@@ -279,9 +262,7 @@ func (c *iamClient) DetachPolicy(namespace string, param *DetachPolicyParameters
 		}
 	}
 
-	h := map[string]string{
-		"x-emc-namespace": namespace,
-	}
+	h := client.NamespaceScope(namespace)
 	bytes, err := c.apiClient.Post("/iam", nil, query, h)
 	if err != nil {
 		return nil, err
@@ -307,9 +288,7 @@ func (c *iamClient) CreateAccessKey(namespace string, param *CreateAccessKeyPara
 		}
 	}
 
-	h := map[string]string{
-		"x-emc-namespace": namespace,
-	}
+	h := client.NamespaceScope(namespace)
 	bytes, err := c.apiClient.Post("/iam", nil, query, h)
 	if err != nil {
 		return nil, err
@@ -338,9 +317,7 @@ func (c *iamClient) DeleteAccessKey(namespace string, param *DeleteAccessKeyPara
 		}
 	}
 
-	h := map[string]string{
-		"x-emc-namespace": namespace,
-	}
+	h := client.NamespaceScope(namespace)
 	bytes, err := c.apiClient.Post("/iam", nil, query, h)
 	if err != nil {
 		return nil, err
@@ -372,9 +349,7 @@ func (c *iamClient) UpdateAccessKey(namespace string, param *UpdateAccessKeyPara
 		}
 	}
 
-	h := map[string]string{
-		"x-emc-namespace": namespace,
-	}
+	h := client.NamespaceScope(namespace)
 	bytes, err := c.apiClient.Post("/iam", nil, query, h)
 	if err != nil {
 		return nil, err
@@ -412,16 +387,14 @@ func (c *iamClient) CreateUser(namespace string, param *CreateUserParameters) (*
 		}
 	}
 
-	h := map[string]string{
-		"x-emc-namespace": namespace,
-	}
-	bytes, err := c.apiClient.Post("/iam", nil, query, h)
+	h := client.NamespaceScope(namespace)
+	r, err := c.apiClient.Create("/iam", nil, query, h)
 	if err != nil {
 		return nil, err
 	}
 
 	resp := &CreateUserResp{}
-	if err = json.Unmarshal(bytes, resp); err != nil {
+	if err = json.Unmarshal(r.Body, resp); err != nil {
 		log.Println("failed to decode response for create user", err)
 	}
 	return resp, err
@@ -438,9 +411,7 @@ func (c *iamClient) DeleteUser(namespace string, param *DeleteUserParameters) (*
 	}
 	query.Add("Action", "DeleteUser")
 
-	h := map[string]string{
-		"x-emc-namespace": namespace,
-	}
+	h := client.NamespaceScope(namespace)
 	bytes, err := c.apiClient.Post("/iam", nil, query, h)
 	if err != nil {
 		return nil, err
@@ -469,9 +440,7 @@ func (c *iamClient) GetUserAttachedPolicyList(namespace string, param *GetUserAt
 		}
 	}
 
-	h := map[string]string{
-		"x-emc-namespace": namespace,
-	}
+	h := client.NamespaceScope(namespace)
 
 	bytes, err := c.apiClient.Get("/iam", query, h)
 	if err != nil {
@@ -486,7 +455,7 @@ func (c *iamClient) GetUserAttachedPolicyList(namespace string, param *GetUserAt
 }
 
 // provides EcsIamClient for give handler to EcsClient
-func GetEcsIamClient(apiClient client.EcsClient) IamClient {
+func GetEcsIamClient(apiClient client.Session) IamClient {
 	return &iamClient{
 		apiClient: apiClient,
 	}
@@ -507,7 +476,7 @@ func (c *iamClient) GetPolicyVersion(namespace string, param *GetPolicyVersionPa
 		}
 	}
 
-	bytes, err := c.apiClient.Get("/iam", query, nil)
+	bytes, err := c.apiClient.Get("/iam", query, client.NamespaceScope(namespace))
 	if err != nil {
 		return nil, err
 	}
@@ -534,7 +503,7 @@ func (c *iamClient) ListPolicyUsers(namespace string, param *ListPolicyUsersPara
 		}
 	}
 
-	bytes, err := c.apiClient.Get("/iam", query, nil)
+	bytes, err := c.apiClient.Get("/iam", query, client.NamespaceScope(namespace))
 	if err != nil {
 		return nil, err
 	}
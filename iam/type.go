@@ -1,6 +1,10 @@
 package iam
 
-import "time"
+import (
+	"time"
+
+	client "github.com/coredgeio/goecsclient"
+)
 
 type CreatePolicyParameters struct {
 	Description    string
@@ -168,8 +172,8 @@ type UpdateAccessKeyResp struct {
 }
 
 type ListPoliciesParameters struct {
-	Marker            string
-	MaxItems          int
+	// Marker and MaxItems of the IAM api
+	client.ListOptions
 	OnlyAttached      string
 	PathPrefix        string
 	PolicyUsageFilter string
@@ -0,0 +1,46 @@
+package goecsclient
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// ListOptions are the paging parameters accepted by all the list calls,
+// Marker is the NextMarker of the previous page, empty for the first
+// page. Limit is the max number of items in the page, zero leaves it to
+// the ECS default
+type ListOptions struct {
+	Marker string
+	Limit  int
+}
+
+// names of the query params carrying paging parameters of an endpoint
+type PageParams struct {
+	Marker string
+	Limit  string
+}
+
+var (
+	// paging params of the management api, eg. bucket and user lists
+	MgmtPageParams = PageParams{Marker: "marker", Limit: "limit"}
+	// paging params of the IAM api
+	IAMPageParams = PageParams{Marker: "Marker", Limit: "MaxItems"}
+)
+
+// adds the paging parameters to query using the param names of the
+// endpoint, query is allocated if nil and any parameter is set
+func (o *ListOptions) AddToQuery(query url.Values, p PageParams) url.Values {
+	if o == nil || (o.Marker == "" && o.Limit == 0) {
+		return query
+	}
+	if query == nil {
+		query = url.Values{}
+	}
+	if o.Marker != "" {
+		query.Add(p.Marker, o.Marker)
+	}
+	if o.Limit != 0 {
+		query.Add(p.Limit, strconv.Itoa(o.Limit))
+	}
+	return query
+}
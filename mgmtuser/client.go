@@ -0,0 +1,88 @@
+package mgmtuser
+
+import (
+	"encoding/json"
+	"log"
+	"net/url"
+
+	client "github.com/coredgeio/goecsclient"
+	"github.com/coredgeio/goecsclient/errors"
+)
+
+// MgmtUserClient manages settings of ECS local management users
+type MgmtUserClient interface {
+	GetPasswordPolicy() (*PasswordPolicy, error)
+	SetPasswordPolicy(p PasswordPolicy) error
+	LogoutAllSessions() error
+	ListSystemRoles() ([]string, error)
+}
+
+type mgmtUserClient struct {
+	apiClient client.Session
+}
+
+func (c *mgmtUserClient) GetPasswordPolicy() (*PasswordPolicy, error) {
+	bytes, err := c.apiClient.Get("/vdc/users/password-policy", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &PasswordPolicy{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get password policy", err)
+	}
+	return resp, err
+}
+
+// replaces the password policy, all the rules are sent so rules left as
+// zero are disabled. policy applies to passwords set after the update
+func (c *mgmtUserClient) SetPasswordPolicy(p PasswordPolicy) error {
+	if p.MinLength < 0 || p.MinUpperCase < 0 || p.MinLowerCase < 0 || p.MinNumeric < 0 ||
+		p.MinSpecial < 0 || p.ExpiryDays < 0 || p.HistoryCount < 0 || p.MaxLoginAttempts < 0 {
+		return errors.Wrap("password policy rules cannot be negative")
+	}
+	if p.MinUpperCase+p.MinLowerCase+p.MinNumeric+p.MinSpecial > p.MinLength && p.MinLength != 0 {
+		return errors.Wrap("password policy requires more characters than its min length")
+	}
+	data, err := json.Marshal(&p)
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/vdc/users/password-policy", data, nil, nil)
+	return err
+}
+
+// invalidates all the tokens of the management user the client is logged
+// in as, across all the sessions of the user. ECS does not provide
+// listing or revoking sessions individually or of other users, which is
+// the closest available for logging out during incident response. token
+// of the client is invalidated as well, Refresh has to be performed on
+// the client before making further requests
+func (c *mgmtUserClient) LogoutAllSessions() error {
+	query := url.Values{}
+	query.Set("force", "true")
+	_, err := c.apiClient.Get("/logout", query, nil)
+	return err
+}
+
+// provides the roles which can be held by management users. ECS does not
+// provide an endpoint listing the roles, so this is the documented set of
+// roles, kept in the client for role assignment to stay in one place.
+// error is always nil, kept for when ECS exposes the roles. this client
+// does not assign roles to users, the set is meant for validating input
+// before assigning them
+func (c *mgmtUserClient) ListSystemRoles() ([]string, error) {
+	return []string{
+		RoleSystemAdmin,
+		RoleSystemMonitor,
+		RoleSecurityAdmin,
+		RoleNamespaceAdmin,
+	}, nil
+}
+
+// provides EcsMgmtUserClient for given handler to EcsClient
+func GetEcsMgmtUserClient(apiClient client.Session) MgmtUserClient {
+	return &mgmtUserClient{
+		apiClient: apiClient,
+	}
+}
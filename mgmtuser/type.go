@@ -0,0 +1,28 @@
+package mgmtuser
+
+// roles of management users as documented by ECS
+const (
+	RoleSystemAdmin    = "SYSTEM_ADMIN"
+	RoleSystemMonitor  = "SYSTEM_MONITOR"
+	RoleSecurityAdmin  = "SECURITY_ADMIN"
+	RoleNamespaceAdmin = "NAMESPACE_ADMIN"
+)
+
+// password policy enforced on local management users, counts of zero
+// indicate the corresponding rule is not enforced
+type PasswordPolicy struct {
+	MinLength int `json:"min_length"`
+
+	// complexity rules, minimum number of characters of each class
+	MinUpperCase int `json:"min_upper_case"`
+	MinLowerCase int `json:"min_lower_case"`
+	MinNumeric   int `json:"min_numeric"`
+	MinSpecial   int `json:"min_special"`
+
+	// number of days after which the password expires
+	ExpiryDays int `json:"expiry_days"`
+	// number of previous passwords which cannot be reused
+	HistoryCount int `json:"history_count"`
+	// number of failed logins after which the user is locked out
+	MaxLoginAttempts int `json:"max_login_attempts"`
+}
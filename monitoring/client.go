@@ -0,0 +1,264 @@
+package monitoring
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"log"
+	"net/url"
+	"strconv"
+
+	client "github.com/coredgeio/goecsclient"
+	"github.com/coredgeio/goecsclient/errors"
+)
+
+// MonitoringClient manages the destinations ECS forwards its logs and
+// alerts to
+type MonitoringClient interface {
+	GetSyslogServers() (*SyslogServerList, error)
+	SetSyslogServers(servers []SyslogServer) error
+	GetSnmpTargets() (*SnmpTargetList, error)
+	SetSnmpTargets(targets []SnmpTarget) error
+	GetCapacityThresholds() (*CapacityThresholds, error)
+	SetCapacityThreshold(storagePoolID string, warningPercent, criticalPercent int) error
+	ListActiveAlerts() (*AlertList, error)
+	AcknowledgeAlert(id string) error
+}
+
+type monitoringClient struct {
+	apiClient client.Session
+}
+
+func (c *monitoringClient) GetSyslogServers() (*SyslogServerList, error) {
+	bytes, err := c.apiClient.Get("/vdc/syslog/config", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &SyslogServerList{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get syslog servers", err)
+	}
+	return resp, err
+}
+
+// makes the configured syslog servers match the given ones, servers are
+// identified by address and port. servers not in the list are removed,
+// missing ones are added and the ones differing in other settings are
+// updated. ID of the given servers is ignored
+func (c *monitoringClient) SetSyslogServers(servers []SyslogServer) error {
+	desired := map[string]*SyslogServer{}
+	for i := range servers {
+		s := &servers[i]
+		if s.Server == "" || s.Port <= 0 {
+			return errors.Wrap("syslog server requires address and port")
+		}
+		key := destKey(s.Server, s.Port)
+		if _, ok := desired[key]; ok {
+			return errors.Wrap("duplicate syslog server " + key)
+		}
+		desired[key] = s
+	}
+	current, err := c.GetSyslogServers()
+	if err != nil {
+		return err
+	}
+	for _, cur := range current.Servers {
+		key := destKey(cur.Server, cur.Port)
+		want, ok := desired[key]
+		if !ok {
+			if _, err = c.apiClient.Delete("/vdc/syslog/config/"+cur.ID, nil, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		delete(desired, key)
+		update := *want
+		update.ID = cur.ID
+		if update == *cur {
+			continue
+		}
+		update.ID = ""
+		data, err := json.Marshal(&update)
+		if err != nil {
+			return err
+		}
+		if _, err = c.apiClient.Put("/vdc/syslog/config/"+cur.ID, data, nil, nil); err != nil {
+			return err
+		}
+	}
+	// added in the given order
+	for i := range servers {
+		s := servers[i]
+		if _, ok := desired[destKey(s.Server, s.Port)]; !ok {
+			continue
+		}
+		s.ID = ""
+		data, err := json.Marshal(&s)
+		if err != nil {
+			return err
+		}
+		_, err = c.apiClient.Create("/vdc/syslog/config", data, nil, nil)
+		if err != nil && !stderrors.Is(err, errors.ErrCreatedByEarlierAttempt) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *monitoringClient) GetSnmpTargets() (*SnmpTargetList, error) {
+	bytes, err := c.apiClient.Get("/vdc/snmp/config", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &SnmpTargetList{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get snmp targets", err)
+	}
+	return resp, err
+}
+
+// makes the configured SNMP trap targets match the given ones, same as
+// SetSyslogServers
+func (c *monitoringClient) SetSnmpTargets(targets []SnmpTarget) error {
+	desired := map[string]*SnmpTarget{}
+	for i := range targets {
+		t := &targets[i]
+		if t.Server == "" || t.Port <= 0 {
+			return errors.Wrap("snmp target requires address and port")
+		}
+		key := destKey(t.Server, t.Port)
+		if _, ok := desired[key]; ok {
+			return errors.Wrap("duplicate snmp target " + key)
+		}
+		desired[key] = t
+	}
+	current, err := c.GetSnmpTargets()
+	if err != nil {
+		return err
+	}
+	for _, cur := range current.Targets {
+		key := destKey(cur.Server, cur.Port)
+		want, ok := desired[key]
+		if !ok {
+			if _, err = c.apiClient.Delete("/vdc/snmp/config/"+cur.ID, nil, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		delete(desired, key)
+		update := *want
+		update.ID = cur.ID
+		if update == *cur {
+			continue
+		}
+		update.ID = ""
+		data, err := json.Marshal(&update)
+		if err != nil {
+			return err
+		}
+		if _, err = c.apiClient.Put("/vdc/snmp/config/"+cur.ID, data, nil, nil); err != nil {
+			return err
+		}
+	}
+	for i := range targets {
+		t := targets[i]
+		if _, ok := desired[destKey(t.Server, t.Port)]; !ok {
+			continue
+		}
+		t.ID = ""
+		data, err := json.Marshal(&t)
+		if err != nil {
+			return err
+		}
+		_, err = c.apiClient.Create("/vdc/snmp/config", data, nil, nil)
+		if err != nil && !stderrors.Is(err, errors.ErrCreatedByEarlierAttempt) {
+			return err
+		}
+	}
+	return nil
+}
+
+// provides the capacity alert thresholds of all the storage pools
+func (c *monitoringClient) GetCapacityThresholds() (*CapacityThresholds, error) {
+	bytes, err := c.apiClient.Get("/vdc/capacity/thresholds", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &CapacityThresholds{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get capacity thresholds", err)
+	}
+	return resp, err
+}
+
+// sets the fill levels at which alerts are raised for the storage pool,
+// warning has to be below critical
+func (c *monitoringClient) SetCapacityThreshold(storagePoolID string, warningPercent, criticalPercent int) error {
+	if storagePoolID == "" {
+		return errors.Wrap("storage pool id is required")
+	}
+	if warningPercent <= 0 || criticalPercent > 100 || warningPercent >= criticalPercent {
+		return errors.Wrap("capacity thresholds must satisfy 0 < warning < critical <= 100")
+	}
+	data, err := json.Marshal(&StoragePoolThreshold{
+		StoragePoolID:   storagePoolID,
+		WarningPercent:  warningPercent,
+		CriticalPercent: criticalPercent,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/vdc/capacity/thresholds/"+storagePoolID, data, nil, nil)
+	return err
+}
+
+// provides all the alerts which are not yet acknowledged, all the pages
+// are fetched
+func (c *monitoringClient) ListActiveAlerts() (*AlertList, error) {
+	alerts := &AlertList{}
+	marker := ""
+	for {
+		query := url.Values{}
+		query.Set("acknowledged", "false")
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		bytes, err := c.apiClient.Get("/vdc/alerts", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp := &AlertList{}
+		if err = json.Unmarshal(bytes, resp); err != nil {
+			log.Println("failed to decode response for list active alerts", err)
+			return nil, err
+		}
+		alerts.Alerts = append(alerts.Alerts, resp.Alerts...)
+		if resp.NextMarker == "" || resp.NextMarker == marker {
+			return alerts, nil
+		}
+		marker = resp.NextMarker
+	}
+}
+
+// marks the alert as handled, acknowledged alerts are retained in the
+// alert history but no longer listed as active
+func (c *monitoringClient) AcknowledgeAlert(id string) error {
+	if id == "" {
+		return errors.Wrap("alert id is required")
+	}
+	_, err := c.apiClient.Put("/vdc/alerts/"+id+"/acknowledgment", nil, nil, nil)
+	return err
+}
+
+func destKey(server string, port int) string {
+	return server + ":" + strconv.Itoa(port)
+}
+
+// provides EcsMonitoringClient for given handler to EcsClient
+func GetEcsMonitoringClient(apiClient client.Session) MonitoringClient {
+	return &monitoringClient{
+		apiClient: apiClient,
+	}
+}
@@ -0,0 +1,58 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Severity of alert, ordered from least to most severe so that levels can
+// be compared, eg. sev >= SeverityError
+type Severity int
+
+const (
+	// severity reported by ECS is not one of the known ones
+	SeverityUnknown Severity = iota
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+var severityNames = map[Severity]string{
+	SeverityInfo:     "INFO",
+	SeverityWarning:  "WARNING",
+	SeverityError:    "ERROR",
+	SeverityCritical: "CRITICAL",
+}
+
+// parses severity as reported by ECS ignoring the case, SeverityUnknown
+// for unrecognized values
+func ParseSeverity(s string) Severity {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	for sev, name := range severityNames {
+		if name == s {
+			return sev
+		}
+	}
+	return SeverityUnknown
+}
+
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return "UNKNOWN"
+}
+
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s *Severity) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	*s = ParseSeverity(name)
+	return nil
+}
@@ -0,0 +1,72 @@
+package monitoring
+
+// transport protocols of syslog server
+const (
+	SyslogProtocolUDP = "UDP"
+	SyslogProtocolTCP = "TCP"
+	SyslogProtocolTLS = "TLS"
+)
+
+// external syslog server receiving the audit and system logs of ECS.
+// messages less severe than Severity are not forwarded, eg. "warning"
+type SyslogServer struct {
+	ID       string `json:"syslog_id,omitempty"`
+	Server   string `json:"server"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	Severity string `json:"severity,omitempty"`
+}
+
+type SyslogServerList struct {
+	Servers []*SyslogServer `json:"syslog_server,omitempty"`
+}
+
+// versions of SNMP trap
+const (
+	SnmpVersion2c = "V2"
+	SnmpVersion3  = "V3"
+)
+
+// destination of SNMP traps raised for ECS alerts, traps less severe
+// than Severity are not sent
+type SnmpTarget struct {
+	ID       string `json:"target_id,omitempty"`
+	Server   string `json:"server"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol,omitempty"`
+	Version  string `json:"version,omitempty"`
+	// community of v2c traps
+	Community string `json:"community,omitempty"`
+	Severity  string `json:"severity,omitempty"`
+}
+
+type SnmpTargetList struct {
+	Targets []*SnmpTarget `json:"snmp_target,omitempty"`
+}
+
+// fill levels of a storage pool at which ECS raises capacity alerts, in
+// percent of the usable capacity
+type StoragePoolThreshold struct {
+	StoragePoolID   string `json:"storage_pool_id"`
+	WarningPercent  int    `json:"warning_threshold"`
+	CriticalPercent int    `json:"critical_threshold"`
+}
+
+type CapacityThresholds struct {
+	StoragePools []*StoragePoolThreshold `json:"storage_pool_threshold,omitempty"`
+}
+
+type Alert struct {
+	ID           string   `json:"id,omitempty"`
+	Type         string   `json:"type,omitempty"`
+	Severity     Severity `json:"severity,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Namespace    string   `json:"namespace,omitempty"`
+	Timestamp    string   `json:"timestamp,omitempty"`
+	Acknowledged bool     `json:"acknowledged,omitempty"`
+}
+
+type AlertList struct {
+	Alerts     []*Alert `json:"alert,omitempty"`
+	NextMarker string   `json:"NextMarker,omitempty"`
+}
@@ -1,10 +1,36 @@
 package namespace
 
 import (
+	"context"
 	"encoding/json"
+	stderrors "errors"
+	"fmt"
 	"log"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	client "github.com/coredgeio/goecsclient"
+	"github.com/coredgeio/goecsclient/bucket"
+	"github.com/coredgeio/goecsclient/errors"
+	"github.com/coredgeio/goecsclient/objectuser"
+	"github.com/coredgeio/goecsclient/replicationgroup"
+)
+
+const (
+	// largest default bucket block size in GB accepted by ECS
+	MaxDefaultBucketBlockSize = int64(math.MaxInt32)
+
+	// number of namespaces fetched in parallel for quota report
+	quotaReportConcurrency = 8
+	// number of buckets and users fetched in parallel for export
+	exportConcurrency = 8
+
+	waitBaseBackoff = 500 * time.Millisecond
+	waitMaxBackoff  = 10 * time.Second
 )
 
 type NamespaceClient interface {
@@ -12,38 +38,115 @@ type NamespaceClient interface {
 	DeleteNamespace(namespace string) error
 	UpdateNamespace(namespace string, req *UpdateNamespaceReq) error
 	SetNamespaceQuota(namespace string, req *SetNamespaceQuotaReq) error
+	GetNamespaceQuota(namespace string) (*NamespaceQuota, error)
+	SetNamespaceQuotaIfUnchanged(namespace string, expected, desired NamespaceQuota) error
+	SetNamespaceDefaultBucketBlockSize(namespace string, gb int64) error
+	GetNamespaceList() (*NamespaceListResp, error)
+	ListNamespaces(opts *client.ListOptions) (*NamespaceListResp, error)
+	GetNamespace(namespace string) (*GetNamespaceResp, error)
+	GetNamespaceAdmins(namespace string) ([]string, error)
+	SetNamespaceAdmins(namespace string, admins []string) error
+	ListAdministeredNamespaces(userID string) (*NamespaceListResp, error)
+	PurgeNamespace(ctx context.Context, namespace string, concurrency int) ([]client.BulkResult[string], error)
+	GetNamespaceBillingInfo(namespace string) (*NamespaceBillingInfoResp, error)
+	QuotaReport(ctx context.Context) ([]NamespaceUsage, error)
+	ExportNamespace(ctx context.Context, namespace string) (*NamespaceExport, error)
+	ImportNamespace(ctx context.Context, export *NamespaceExport) error
+	ListNamespaceReplicationGroups(namespace string) (*replicationgroup.ReplicationGroupListResp, error)
+	WaitForNamespace(ctx context.Context, namespace string, timeout time.Duration) error
 }
 
 type namespaceClient struct {
-	apiClient client.EcsClient
+	apiClient client.Session
+
+	// when set, replication groups of the namespace are validated before
+	// creation
+	rgCache *replicationgroup.Cache
+}
+
+// Option allows configuring optional behaviour of namespace client
+type Option func(*namespaceClient)
+
+// validates that the replication groups (vpools) requested for a
+// namespace exist before creating it, failing with
+// ErrReplicationGroupNotFound otherwise. replication groups are looked up
+// using the given cache which can be shared with other clients. they can
+// also be given by name, which is resolved to the id
+func WithReplicationGroupValidation(cache *replicationgroup.Cache) Option {
+	return func(c *namespaceClient) {
+		c.rgCache = cache
+	}
 }
 
 // Create Namespace
 func (c *namespaceClient) CreateNamespace(req *CreateNamespaceReq) (*CreateNamespaceResp, error) {
+	if c.rgCache != nil {
+		resolved := *req
+		var err error
+		if resolved.DefaultDataServicesVpool, err = c.resolveVpool(req.DefaultDataServicesVpool); err != nil {
+			return nil, err
+		}
+		if resolved.AllowedVpoolsList, err = c.resolveVpools(req.AllowedVpoolsList); err != nil {
+			return nil, err
+		}
+		if resolved.DisallowedVpoolsList, err = c.resolveVpools(req.DisallowedVpoolsList); err != nil {
+			return nil, err
+		}
+		req = &resolved
+	}
 	data, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
 
-	bytes, err := c.apiClient.Post("/object/namespaces/namespace", data, nil, nil)
+	r, err := c.apiClient.Create("/object/namespaces/namespace", data, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	resp := &CreateNamespaceResp{}
-	if err = json.Unmarshal(bytes, resp); err != nil {
+	if err = json.Unmarshal(r.Body, resp); err != nil {
 		log.Println("failed to decode response for create namespace", err)
 	}
+	resp.Location = r.Header.Get("Location")
 	return resp, err
 }
 
+// provides id of the replication group given by id or name, empty is
+// retained as is
+func (c *namespaceClient) resolveVpool(vpool string) (string, error) {
+	if vpool == "" {
+		return "", nil
+	}
+	rg, err := c.rgCache.Resolve(vpool)
+	if err != nil {
+		return "", err
+	}
+	return rg.ID, nil
+}
+
+func (c *namespaceClient) resolveVpools(vpools []string) ([]string, error) {
+	if vpools == nil {
+		return nil, nil
+	}
+	ids := make([]string, 0, len(vpools))
+	for _, vpool := range vpools {
+		id, err := c.resolveVpool(vpool)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
 // Update Namespace
 func (c *namespaceClient) UpdateNamespace(namespace string, req *UpdateNamespaceReq) error {
 	data, err := json.Marshal(req)
 	if err != nil {
 		return err
 	}
-	_, err = c.apiClient.Put("/object/namespaces/namespace/"+namespace, data, nil)
+	_, err = c.apiClient.Put("/object/namespaces/namespace/"+namespace, data, nil, nil)
 	return err
 }
 
@@ -60,16 +163,602 @@ func (c *namespaceClient) SetNamespaceQuota(namespace string, req *SetNamespaceQ
 	if err != nil {
 		return err
 	}
-	_, err = c.apiClient.Put("/object/namespaces/namespace/"+namespace+"/quota", data, nil)
+	_, err = c.apiClient.Put("/object/namespaces/namespace/"+namespace+"/quota", data, nil, nil)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+func (c *namespaceClient) GetNamespaceQuota(namespace string) (*NamespaceQuota, error) {
+	bytes, err := c.apiClient.Get("/object/namespaces/namespace/"+namespace+"/quota", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &getNamespaceQuotaResp{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get namespace quota", err)
+		return nil, err
+	}
+	return &resp.NamespaceQuota, nil
+}
+
+// sets the quota of namespace to desired only if the current quota is
+// same as expected, failing with ErrPreconditionFailed otherwise. this
+// avoids silently overwriting a quota updated by someone else since it
+// was last read.
+//
+// ECS does not support conditional updates, so the quota is read and
+// verified before writing it. the check is not atomic with the write,
+// updates made within the small window between them are not detected
+func (c *namespaceClient) SetNamespaceQuotaIfUnchanged(namespace string, expected, desired NamespaceQuota) error {
+	current, err := c.GetNamespaceQuota(namespace)
+	if err != nil {
+		return err
+	}
+	if *current != expected {
+		return errors.ErrPreconditionFailed
+	}
+	data, err := json.Marshal(&desired)
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/object/namespaces/namespace/"+namespace+"/quota", data, nil, nil)
+	return err
+}
+
+// sets the default block size (hard quota) in GB applied to buckets
+// created in the namespace from here onwards, existing buckets are not
+// affected
+func (c *namespaceClient) SetNamespaceDefaultBucketBlockSize(namespace string, gb int64) error {
+	if gb <= 0 || gb > MaxDefaultBucketBlockSize {
+		return errors.Wrap("default bucket block size must be between 1 and " +
+			strconv.FormatInt(MaxDefaultBucketBlockSize, 10) + " GB")
+	}
+	return c.UpdateNamespace(namespace, &UpdateNamespaceReq{
+		DefaultBucketBlockSize: gb,
+	})
+}
+
+// lists all the namespaces, fetching every page
+func (c *namespaceClient) GetNamespaceList() (*NamespaceListResp, error) {
+	return c.listAllNamespaces()
+}
+
+// lists a page of namespaces, nil opts lists the first page using the
+// default page size of ECS. NextMarker of the response is set when more
+// namespaces are available
+func (c *namespaceClient) ListNamespaces(opts *client.ListOptions) (*NamespaceListResp, error) {
+	query := opts.AddToQuery(nil, client.MgmtPageParams)
+	bytes, err := c.apiClient.Get("/object/namespaces", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &NamespaceListResp{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get namespace list", err)
+	}
+	return resp, err
+}
+
+// lists the namespaces across all the pages
+func (c *namespaceClient) listAllNamespaces() (*NamespaceListResp, error) {
+	result := &NamespaceListResp{}
+	opts := &client.ListOptions{}
+	for {
+		resp, err := c.ListNamespaces(opts)
+		if err != nil {
+			return nil, err
+		}
+		result.Namespaces = append(result.Namespaces, resp.Namespaces...)
+		if resp.NextMarker == "" || resp.NextMarker == opts.Marker {
+			break
+		}
+		opts.Marker = resp.NextMarker
+	}
+	return result, nil
+}
+
+func (c *namespaceClient) GetNamespace(namespace string) (*GetNamespaceResp, error) {
+	bytes, err := c.apiClient.Get("/object/namespaces/namespace/"+namespace, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &GetNamespaceResp{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get namespace", err)
+	}
+	return resp, err
+}
+
+func (c *namespaceClient) GetNamespaceAdmins(namespace string) ([]string, error) {
+	resp, err := c.GetNamespace(namespace)
+	if err != nil {
+		return nil, err
+	}
+	var admins []string
+	for _, admin := range strings.Split(resp.NamespaceAdmins, ",") {
+		admin = strings.TrimSpace(admin)
+		if admin != "" {
+			admins = append(admins, admin)
+		}
+	}
+	return admins, nil
+}
+
+// lists the namespaces for which the given management user is one of the
+// namespace admins.
+//
+// ECS does not support listing namespaces by admin, so this fetches every
+// namespace to check its admins, costing one request per namespace in
+// the system on top of the namespace list
+func (c *namespaceClient) ListAdministeredNamespaces(userID string) (*NamespaceListResp, error) {
+	nsList, err := c.GetNamespaceList()
+	if err != nil {
+		return nil, err
+	}
+	result := &NamespaceListResp{}
+	for _, ns := range nsList.Namespaces {
+		admins, err := c.GetNamespaceAdmins(ns.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, admin := range admins {
+			if admin == userID {
+				result.Namespaces = append(result.Namespaces, ns)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// replaces the admins of the namespace with the given list, an empty list
+// removes all the admins. other settings of the namespace are preserved
+// since only the admins are sent as part of the update
+func (c *namespaceClient) SetNamespaceAdmins(namespace string, admins []string) error {
+	// UpdateNamespaceReq omits empty admins, which would not allow
+	// removing all the admins
+	data, err := json.Marshal(map[string]string{
+		"namespace_admins": strings.Join(admins, ","),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/object/namespaces/namespace/"+namespace, data, nil, nil)
+	return err
+}
+
+// deletes all the buckets of the namespace along with their objects and
+// then the namespace itself, for decommissioning a tenant. buckets are
+// deleted using upto concurrency parallel requests.
+//
+// ECS empties force deleted buckets in background, so every bucket is
+// polled till it is gone before the namespace is deleted, as long as ctx
+// allows. failure to delete a bucket does not stop deletion of others.
+// result is provided for every bucket, in the order ECS lists them, along
+// with an error joining the errors of the buckets which could not be
+// deleted. the namespace is deleted only if all the buckets were deleted
+func (c *namespaceClient) PurgeNamespace(ctx context.Context, namespace string, concurrency int) ([]client.BulkResult[string], error) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	bClient := bucket.GetEcsBucketClient(c.apiClient)
+	var names []string
+	param := &bucket.BucketListParameters{Namespace: namespace}
+	for {
+		resp, err := bClient.GetList(param)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range resp.Buckets {
+			names = append(names, b.Name)
+		}
+		if resp.NextMarker == "" || resp.NextMarker == param.Marker {
+			break
+		}
+		param.Marker = resp.NextMarker
+	}
+
+	results := make([]client.BulkResult[string], len(names))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, name := range names {
+		results[i].Item = name
+		select {
+		case <-ctx.Done():
+			results[i].Err = fmt.Errorf("bucket %s not deleted: %w", name, ctx.Err())
+			continue
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			err := bClient.ForceDelete(name, namespace)
+			if err == nil {
+				err = bClient.WaitForBucketDeleted(ctx, name, namespace, 0)
+			}
+			if err != nil {
+				results[i].Err = fmt.Errorf("failed to delete bucket %s: %w", name, err)
+			}
+		}(i, name)
+	}
+	wg.Wait()
+	if err := client.BulkError(results); err != nil {
+		return results, err
+	}
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+	return results, c.DeleteNamespace(namespace)
+}
+
+// provides the usage of namespace with size in GB
+func (c *namespaceClient) GetNamespaceBillingInfo(namespace string) (*NamespaceBillingInfoResp, error) {
+	query := url.Values{}
+	query.Add("sizeunit", "GB")
+	bytes, err := c.apiClient.Get("/object/billing/namespace/"+namespace+"/info", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &NamespaceBillingInfoResp{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get namespace billing info", err)
+	}
+	return resp, err
+}
+
+// provides quota and usage of every namespace, eg. for chargeback. quota
+// and billing info of namespaces are fetched in parallel.
+//
+// failure to fetch a namespace does not stop the report, the namespaces
+// fetched are returned along with an error covering the ones that
+// failed. report is in the order namespaces are listed by ECS
+func (c *namespaceClient) QuotaReport(ctx context.Context) ([]NamespaceUsage, error) {
+	nsList, err := c.listAllNamespaces()
+	if err != nil {
+		return nil, err
+	}
+	usages := make([]*NamespaceUsage, len(nsList.Namespaces))
+	errs := make([]error, len(nsList.Namespaces))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, quotaReportConcurrency)
+	for i, ns := range nsList.Namespaces {
+		select {
+		case <-ctx.Done():
+			errs[i] = fmt.Errorf("namespace %s not reported: %w", ns.ID, ctx.Err())
+			continue
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(i int, namespace string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			usage, err := c.namespaceUsage(namespace)
+			if err != nil {
+				errs[i] = fmt.Errorf("failed to get usage of namespace %s: %w", namespace, err)
+				return
+			}
+			usages[i] = usage
+		}(i, ns.ID)
+	}
+	wg.Wait()
+	report := []NamespaceUsage{}
+	for _, usage := range usages {
+		if usage != nil {
+			report = append(report, *usage)
+		}
+	}
+	return report, stderrors.Join(errs...)
+}
+
+func (c *namespaceClient) namespaceUsage(namespace string) (*NamespaceUsage, error) {
+	quota, err := c.GetNamespaceQuota(namespace)
+	if err != nil {
+		return nil, err
+	}
+	billing, err := c.GetNamespaceBillingInfo(namespace)
+	if err != nil {
+		return nil, err
+	}
+	usage := &NamespaceUsage{
+		Namespace:    namespace,
+		Quota:        *quota,
+		TotalObjects: billing.TotalObjects,
+	}
+	if billing.TotalSize != "" {
+		usage.UsedGB, err = strconv.ParseFloat(billing.TotalSize, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return usage, nil
+}
+
+// gathers the settings, quota, buckets with their ACL and object users
+// with their tags of the namespace into a single snapshot. buckets and
+// users are fetched in parallel, export fails if any of them could not be
+// fetched since the snapshot would be incomplete otherwise
+func (c *namespaceClient) ExportNamespace(ctx context.Context, namespace string) (*NamespaceExport, error) {
+	settings, err := c.GetNamespace(namespace)
+	if err != nil {
+		return nil, err
+	}
+	quota, err := c.GetNamespaceQuota(namespace)
+	if err != nil {
+		return nil, err
+	}
+	bClient := bucket.GetEcsBucketClient(c.apiClient)
+	var bucketNames []string
+	bParam := &bucket.BucketListParameters{Namespace: namespace}
+	for {
+		resp, err := bClient.GetList(bParam)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range resp.Buckets {
+			bucketNames = append(bucketNames, b.Name)
+		}
+		if resp.NextMarker == "" || resp.NextMarker == bParam.Marker {
+			break
+		}
+		bParam.Marker = resp.NextMarker
+	}
+	uClient := objectuser.GetEcsObjectUserClient(c.apiClient)
+	var userIDs []string
+	uParam := &objectuser.ObjectUserListParameters{Namespace: namespace}
+	for {
+		resp, err := uClient.GetList(uParam)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range resp.Users {
+			userIDs = append(userIDs, u.UserID)
+		}
+		if resp.NextMarker == "" || resp.NextMarker == uParam.Marker {
+			break
+		}
+		uParam.Marker = resp.NextMarker
+	}
+
+	export := &NamespaceExport{
+		Namespace: namespace,
+		Settings:  settings,
+		Quota:     quota,
+		Buckets:   make([]*BucketExport, len(bucketNames)),
+		Users:     make([]*UserExport, len(userIDs)),
+	}
+	var tasks []func() error
+	for i, name := range bucketNames {
+		tasks = append(tasks, func() error {
+			info, err := bClient.GetInfo(name, namespace)
+			if err != nil {
+				return fmt.Errorf("failed to get bucket %s: %w", name, err)
+			}
+			acl, err := bClient.GetACL(name, namespace)
+			if err != nil {
+				return fmt.Errorf("failed to get acl of bucket %s: %w", name, err)
+			}
+			export.Buckets[i] = &BucketExport{Bucket: info, ACL: acl}
+			return nil
+		})
+	}
+	for i, id := range userIDs {
+		tasks = append(tasks, func() error {
+			tags, err := uClient.GetObjectUserTags(id, namespace)
+			if err != nil {
+				return fmt.Errorf("failed to get tags of user %s: %w", id, err)
+			}
+			export.Users[i] = &UserExport{UserID: id, Tags: tags}
+			return nil
+		})
+	}
+	if err := runConcurrently(ctx, exportConcurrency, tasks); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// recreates the namespace of the snapshot along with its quota, retention
+// classes and buckets, eg. on another system. the namespace must not exist already.
+// replication groups are referred by id, which differ across systems
+// unless resolved by name using WithReplicationGroupValidation.
+//
+// object users and bucket ACLs are not recreated since the client does
+// not support creating them, they are left in the export for the caller
+// to act upon
+func (c *namespaceClient) ImportNamespace(ctx context.Context, export *NamespaceExport) error {
+	if export == nil || export.Settings == nil {
+		return errors.Wrap("namespace export with settings is required")
+	}
+	ns := export.Namespace
+	st := export.Settings
+	_, err := c.CreateNamespace(&CreateNamespaceReq{
+		Namespace:                ns,
+		DefaultDataServicesVpool: st.DefaultDataServicesVpool,
+		AllowedVpoolsList:        st.AllowedVpoolsList,
+		DisallowedVpoolsList:     st.DisallowedVpoolsList,
+		NamespaceAdmins:          st.NamespaceAdmins,
+		UserMapping:              st.UserMapping,
+		IsEncryptionEnabled:      st.IsEncryptionEnabled == "true",
+		DefaultBucketBlockSize:   st.DefaultBucketBlockSize,
+		ExternalGroupAdmins:      st.ExternalGroupAdmins,
+		IsStaleAllowed:           st.IsStaleAllowed,
+		ComplianceEnabled:        st.IsComplianceEnabled,
+	})
+	if err != nil {
+		return err
+	}
+	if q := export.Quota; q != nil && (q.BlockSize > 0 || q.NotificationSize > 0) {
+		err = c.SetNamespaceQuota(ns, &SetNamespaceQuotaReq{
+			BlockSize:        q.BlockSize,
+			NotificationSize: q.NotificationSize,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, rc := range st.RetentionClass {
+		data, err := json.Marshal(&retentionClassCreateReq{
+			Name:   rc.Name,
+			Period: rc.Period,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = c.apiClient.Create("/object/namespaces/namespace/"+ns+"/retention", data, nil, nil)
+		if err != nil && !stderrors.Is(err, errors.ErrCreatedByEarlierAttempt) {
+			return fmt.Errorf("failed to create retention class %s: %w", rc.Name, err)
+		}
+	}
+	bClient := bucket.GetEcsBucketClient(c.apiClient)
+	var tasks []func() error
+	for _, b := range export.Buckets {
+		if b == nil || b.Bucket == nil {
+			continue
+		}
+		info := b.Bucket
+		// ECS reports the api type in upper case, eg. S3
+		headType := bucket.HeadType(strings.ToLower(info.APIType))
+		req := &bucket.BucketCreateReq{
+			Name:                info.Name,
+			Namespace:           ns,
+			Vpool:               info.Vpool,
+			BlockSize:           int64(info.BlockSize),
+			NotificationSize:    int64(info.NotificationSize),
+			FilesystemEnabled:   info.FsAccessEnabled,
+			HeadType:            headType,
+			IsEncryptionEnabled: info.IsEncryptionEnabled == "true",
+			IsStaleAllowed:      info.IsStaleAllowed,
+			IsTsoReadOnly:       info.IsTsoReadOnly,
+			Owner:               info.Owner,
+			Retention:           int64(info.Retention),
+			DefaultGroup:        info.DefaultGroup,
+		}
+		req.TagSet = append(req.TagSet, info.TagSet...)
+		tasks = append(tasks, func() error {
+			_, err := bClient.Create(req)
+			if err != nil && !stderrors.Is(err, errors.ErrCreatedByEarlierAttempt) {
+				return fmt.Errorf("failed to create bucket %s: %w", req.Name, err)
+			}
+			return nil
+		})
+	}
+	return runConcurrently(ctx, exportConcurrency, tasks)
+}
+
+// provides the replication groups buckets of the namespace can be created
+// in. a replication group is usable if it allows all namespaces or grants
+// the namespace explicitly, and the namespace itself permits it, ie. it is
+// in the allowed list of the namespace when one is set and not in its
+// disallowed list. inactive replication groups are left out
+func (c *namespaceClient) ListNamespaceReplicationGroups(namespace string) (*replicationgroup.ReplicationGroupListResp, error) {
+	ns, err := c.GetNamespace(namespace)
+	if err != nil {
+		return nil, err
+	}
+	rgs, err := replicationgroup.GetEcsReplicationGroupClient(c.apiClient).GetList()
+	if err != nil {
+		return nil, err
+	}
+	contains := func(list []string, s string) bool {
+		for _, v := range list {
+			if v == s {
+				return true
+			}
+		}
+		return false
+	}
+	resp := &replicationgroup.ReplicationGroupListResp{}
+	for _, rg := range rgs.ReplicationGroups {
+		if rg.Inactive {
+			continue
+		}
+		if !rg.IsAllowAllNamespaces && !contains(rg.Namespaces, namespace) {
+			continue
+		}
+		if len(ns.AllowedVpoolsList) != 0 && !contains(ns.AllowedVpoolsList, rg.ID) {
+			continue
+		}
+		if contains(ns.DisallowedVpoolsList, rg.ID) {
+			continue
+		}
+		resp.ReplicationGroups = append(resp.ReplicationGroups, rg)
+	}
+	return resp, nil
+}
+
+// polls the namespace till it is found, eg. while a newly created
+// namespace propagates to the other VDCs of a federation when the client
+// is connected to one of them. polls are made with backoff in between,
+// till the timeout or the context expires. zero timeout waits as long as
+// the context allows
+func (c *namespaceClient) WaitForNamespace(ctx context.Context, namespace string, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	delay := waitBaseBackoff
+	for {
+		_, err := c.GetNamespace(namespace)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("namespace %s not available: %w, last error: %v", namespace, ctx.Err(), err)
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > waitMaxBackoff {
+			delay = waitMaxBackoff
+		}
+	}
+}
+
+// runs the tasks using upto concurrency goroutines, tasks not started
+// once ctx is done are skipped. all the started tasks are waited for and
+// their errors joined
+func runConcurrently(ctx context.Context, concurrency int, tasks []func() error) error {
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for _, task := range tasks {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return stderrors.Join(append(errs, ctx.Err())...)
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(task func() error) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := task(); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(task)
+	}
+	wg.Wait()
+	return stderrors.Join(errs...)
+}
+
 // provides EcsNamespaceClient for give handler to EcsClient
-func GetEcsNamespaceClient(apiClient client.EcsClient) NamespaceClient {
-	return &namespaceClient{
+func GetEcsNamespaceClient(apiClient client.Session, opts ...Option) NamespaceClient {
+	c := &namespaceClient{
 		apiClient: apiClient,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
@@ -0,0 +1,123 @@
+package namespace
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	client "github.com/coredgeio/goecsclient"
+)
+
+// serves the namespace list in two pages along with quota and billing
+// info of every namespace
+func newPagedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(client.DefaultTokenHeader, "token")
+		switch {
+		case r.URL.Path == "/object/namespaces" && r.URL.Query().Get("marker") == "":
+			w.Write([]byte(`{"namespace":[{"id":"ns1"}],"NextMarker":"ns2"}`))
+		case r.URL.Path == "/object/namespaces":
+			w.Write([]byte(`{"namespace":[{"id":"ns2"}]}`))
+		case strings.HasSuffix(r.URL.Path, "/quota"):
+			w.Write([]byte(`{"blockSize":10,"notificationSize":5}`))
+		case strings.HasPrefix(r.URL.Path, "/object/billing/namespace/"):
+			w.Write([]byte(`{"total_size":"1.5","total_objects":3}`))
+		default:
+			w.Write([]byte("{}"))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestQuotaReportAllPages(t *testing.T) {
+	srv := newPagedServer(t)
+	c, err := client.CreateEcsClientWithUserCred("user", "pass", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	report, err := GetEcsNamespaceClient(c).QuotaReport(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report) != 2 || report[0].Namespace != "ns1" || report[1].Namespace != "ns2" {
+		t.Fatalf("expected namespaces of both pages, got %+v", report)
+	}
+}
+
+func TestListAdministeredNamespacesAllPages(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(client.DefaultTokenHeader, "token")
+		switch {
+		case r.URL.Path == "/object/namespaces" && r.URL.Query().Get("marker") == "":
+			w.Write([]byte(`{"namespace":[{"id":"ns1"}],"NextMarker":"ns2"}`))
+		case r.URL.Path == "/object/namespaces":
+			w.Write([]byte(`{"namespace":[{"id":"ns2"}]}`))
+		case r.URL.Path == "/object/namespaces/namespace/ns2":
+			w.Write([]byte(`{"id":"ns2","namespace_admins":"admin1"}`))
+		default:
+			w.Write([]byte("{}"))
+		}
+	}))
+	defer srv.Close()
+	c, err := client.CreateEcsClientWithUserCred("user", "pass", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	resp, err := GetEcsNamespaceClient(c).ListAdministeredNamespaces("admin1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Namespaces) != 1 || resp.Namespaces[0].ID != "ns2" {
+		t.Fatalf("expected namespace of second page, got %+v", resp.Namespaces)
+	}
+}
+
+func TestImportNamespace(t *testing.T) {
+	bodies := make(chan string, 4)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(client.DefaultTokenHeader, "token")
+		if r.Method == "POST" && (r.URL.Path == "/object/bucket" || strings.HasSuffix(r.URL.Path, "/retention")) {
+			b, _ := io.ReadAll(r.Body)
+			bodies <- r.URL.Path + " " + string(b)
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+	c, err := client.CreateEcsClientWithUserCred("user", "pass", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	export := &NamespaceExport{}
+	if err = json.Unmarshal([]byte(`{
+		"namespace": "ns1",
+		"settings": {"retention_class": [{"name": "legal", "period": 86400}]},
+		"buckets": [{"bucket": {"name": "b1", "api_type": "S3"}}]
+	}`), export); err != nil {
+		t.Fatal(err)
+	}
+	if err = GetEcsNamespaceClient(c).ImportNamespace(context.Background(), export); err != nil {
+		t.Fatal(err)
+	}
+	close(bodies)
+	var got []string
+	for b := range bodies {
+		got = append(got, b)
+	}
+	if len(got) != 2 ||
+		!strings.Contains(got[0], `/object/namespaces/namespace/ns1/retention {"name":"legal","period":86400}`) ||
+		!strings.Contains(got[1], `"head_type":"s3"`) {
+		t.Fatalf("expected retention class and s3 bucket to be created, got %v", got)
+	}
+}
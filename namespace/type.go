@@ -1,5 +1,7 @@
 package namespace
 
+import "github.com/coredgeio/goecsclient/bucket"
+
 type CreateNamespaceReq struct {
 	Namespace                string   `json:"namespace,omitempty"`
 	DefaultObjectProject     string   `json:"default_object_project,omitempty"`
@@ -72,8 +74,27 @@ type CreateNamespaceResp struct {
 		} `json:"link,omitempty"`
 	} `json:"vdc,omitempty"`
 	Internal bool `json:"internal,omitempty"`
+
+	// url of the created namespace as provided by Location header of the
+	// response, empty if not provided by ECS
+	Location string `json:"-"`
 }
 
+type NamespaceListResp struct {
+	Namespaces []struct {
+		ID   string `json:"id,omitempty"`
+		Name string `json:"name,omitempty"`
+		Link struct {
+			Rel  string `json:"rel,omitempty"`
+			Href string `json:"href,omitempty"`
+		} `json:"link,omitempty"`
+	} `json:"namespace,omitempty"`
+	NextMarker string `json:"NextMarker,omitempty"`
+}
+
+// details of an existing namespace, same as the ones provided on creation
+type GetNamespaceResp = CreateNamespaceResp
+
 type UpdateNamespaceReq struct {
 	DefaultDataServicesVpool              string   `json:"default_data_services_vpool,omitempty"`
 	VpoolsAddedToAllowedVpoolsList        []string `json:"vpools_added_to_allowed_vpools_list,omitempty"`
@@ -100,7 +121,61 @@ type UpdateNamespaceReq struct {
 	NewRootUserPassword          string `json:"new_root_user_password,omitempty"`
 }
 
+// quota of namespace in GB, -1 for a limit indicates it is not set
+type NamespaceQuota struct {
+	BlockSize        int64 `json:"blockSize"`
+	NotificationSize int64 `json:"notificationSize"`
+}
+
+type getNamespaceQuotaResp struct {
+	NamespaceQuota
+	Namespace string `json:"namespace,omitempty"`
+}
+
 type SetNamespaceQuotaReq struct {
 	BlockSize int64 `json:"blockSize,omitempty"`
 	NotificationSize int64 `json:"notificationSize,omitempty"`
-}
\ No newline at end of file
+}
+type NamespaceBillingInfoResp struct {
+	Namespace     string `json:"namespace,omitempty"`
+	TotalSize     string `json:"total_size,omitempty"`
+	TotalSizeUnit string `json:"total_size_unit,omitempty"`
+	TotalObjects  int    `json:"total_objects,omitempty"`
+}
+
+type retentionClassCreateReq struct {
+	Name string `json:"name"`
+	// retention period in seconds
+	Period int64 `json:"period"`
+}
+
+// quota of namespace along with its current usage
+type NamespaceUsage struct {
+	Namespace string
+	Quota     NamespaceQuota
+	// usage of the namespace in GB
+	UsedGB       float64
+	TotalObjects int
+}
+
+// snapshot of a namespace for backup or migration, can be marshaled to
+// json. retention classes are part of Settings. secret keys of the users
+// are not exported
+type NamespaceExport struct {
+	Namespace string            `json:"namespace"`
+	Settings  *GetNamespaceResp `json:"settings"`
+	Quota     *NamespaceQuota   `json:"quota"`
+	Buckets   []*BucketExport   `json:"buckets"`
+	Users     []*UserExport     `json:"users"`
+}
+
+// bucket settings including its quota and tags, along with its ACL
+type BucketExport struct {
+	Bucket *bucket.Bucket    `json:"bucket"`
+	ACL    *bucket.BucketACL `json:"acl"`
+}
+
+type UserExport struct {
+	UserID string            `json:"userid"`
+	Tags   map[string]string `json:"tags,omitempty"`
+}
@@ -0,0 +1,536 @@
+package objectuser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	client "github.com/coredgeio/goecsclient"
+	ecserrors "github.com/coredgeio/goecsclient/errors"
+)
+
+type ObjectUserClient interface {
+	GetList(param *ObjectUserListParameters) (*ObjectUserListResp, error)
+	ListSecretKeys(userID, namespace string) (*SecretKeysResp, error)
+	CreateSecretKey(userID string, req *CreateSecretKeyReq) (*CreateSecretKeyResp, error)
+	DeleteSecretKey(userID string, req *DeleteSecretKeyReq) error
+	RotateSecretKey(userID, namespace string) (*CreateSecretKeyResp, error)
+	ListAllSecretKeys(namespace string) (map[string]*SecretKeysResp, error)
+	RevokeAllSecretKeys(userID, namespace string) ([]client.BulkResult[int], error)
+	GetObjectUserTags(userID, namespace string) (map[string]string, error)
+	SetObjectUserTags(userID, namespace string, tags map[string]string) error
+	GetObjectUser(userID, namespace string) (*ObjectUser, error)
+	ListDormantUsers(namespace string, olderThan time.Time) ([]ObjectUser, error)
+	SetObjectUserPassword(userID, namespace, password string) error
+	DeleteObjectUserPassword(userID, namespace string) error
+	SecretKeyInventory(ctx context.Context, namespace string) ([]KeyRecord, error)
+	GetObjectUserQuota(userID, namespace string) (*UserQuota, error)
+	SetObjectUserQuota(userID, namespace string, quota UserQuota) error
+}
+
+// earliest ECS version supporting quota of object users
+const minUserQuotaVersion = "3.6.0.0"
+
+// number of users whose secret keys are fetched in parallel for the
+// inventory
+const inventoryConcurrency = 8
+
+// layouts of timestamps reported by ECS for users and secret keys
+var timestampLayouts = []string{
+	"Mon Jan 02 15:04:05 MST 2006",
+	"2006-01-02 15:04:05.999",
+	time.RFC3339,
+}
+
+type objectUserClient struct {
+	apiClient client.Session
+
+	// expiry in minutes of the key retained on rotation, zero leaves it
+	// without expiry
+	rotationKeyExpiryMins int
+}
+
+// Option allows configuring optional behaviour of object user client
+type Option func(*objectUserClient)
+
+// sets the expiry in minutes of the existing key retained by
+// RotateSecretKey, giving applications that long to move over to the new
+// key. retained key does not expire by default
+func WithRotationKeyExpiry(mins int) Option {
+	return func(c *objectUserClient) {
+		c.rotationKeyExpiryMins = mins
+	}
+}
+
+func (c *objectUserClient) GetList(param *ObjectUserListParameters) (*ObjectUserListResp, error) {
+	var query url.Values
+	if param != nil {
+		if param.Namespace != "" {
+			query = url.Values{}
+			query.Add("namespace", param.Namespace)
+		}
+		query = param.AddToQuery(query, client.MgmtPageParams)
+	}
+
+	bytes, err := c.apiClient.Get("/object/users", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ObjectUserListResp{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get object user list", err)
+	}
+	return resp, err
+}
+
+func (c *objectUserClient) ListSecretKeys(userID, namespace string) (*SecretKeysResp, error) {
+	var query url.Values
+	if namespace != "" {
+		query = url.Values{}
+		query.Add("namespace", namespace)
+	}
+	bytes, err := c.apiClient.Get("/object/user-secret-keys/"+userID, query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &SecretKeysResp{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for list secret keys", err)
+	}
+	return resp, err
+}
+
+func (c *objectUserClient) CreateSecretKey(userID string, req *CreateSecretKeyReq) (*CreateSecretKeyResp, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := c.apiClient.Post("/object/user-secret-keys/"+userID, data, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &CreateSecretKeyResp{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for create secret key", err)
+	}
+	return resp, err
+}
+
+func (c *objectUserClient) DeleteSecretKey(userID string, req *DeleteSecretKeyReq) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Post("/object/user-secret-keys/"+userID+"/deactivate", data, nil, nil)
+	return err
+}
+
+// issues a new secret key for the user while keeping the most recent of
+// the existing keys, so that applications can move over to the new key.
+//
+// since ECS allows at most two keys per user, if the user already has
+// two keys the older one is deleted before the new key is created. key
+// 1 is treated as the older one if the timestamps of the keys are not in
+// a known format. expiry of the key retained is set as per
+// WithRotationKeyExpiry
+func (c *objectUserClient) RotateSecretKey(userID, namespace string) (*CreateSecretKeyResp, error) {
+	keys, err := c.ListSecretKeys(userID, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if keys.SecretKey1 != "" && keys.SecretKey2 != "" {
+		oldest := keys.SecretKey1
+		t1, t2 := parseTimestamp(keys.KeyTimestamp1), parseTimestamp(keys.KeyTimestamp2)
+		if !t1.IsZero() && !t2.IsZero() && t2.Before(t1) {
+			oldest = keys.SecretKey2
+		}
+		err = c.DeleteSecretKey(userID, &DeleteSecretKeyReq{
+			Namespace: namespace,
+			SecretKey: oldest,
+		})
+		if err != nil {
+			log.Println("failed to delete oldest secret key during rotation", err)
+			return nil, err
+		}
+	}
+	return c.CreateSecretKey(userID, &CreateSecretKeyReq{
+		Namespace:             namespace,
+		ExistingKeyExpiryMins: c.rotationKeyExpiryMins,
+	})
+}
+
+// provides secret keys of all the object users of the namespace, keyed
+// by user id.
+//
+// failure to fetch keys of a user does not stop the scan, the keys of
+// remaining users are still returned along with an error naming every
+// user whose keys could not be fetched
+func (c *objectUserClient) ListAllSecretKeys(namespace string) (map[string]*SecretKeysResp, error) {
+	result := map[string]*SecretKeysResp{}
+	var errs []error
+	param := &ObjectUserListParameters{Namespace: namespace}
+	for {
+		users, err := c.GetList(param)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users.Users {
+			keys, err := c.ListSecretKeys(user.UserID, namespace)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to list secret keys of user %s: %w", user.UserID, err))
+				continue
+			}
+			result[user.UserID] = keys
+		}
+		if users.NextMarker == "" || users.NextMarker == param.Marker {
+			break
+		}
+		param.Marker = users.NextMarker
+	}
+	return result, errors.Join(errs...)
+}
+
+// deletes every secret key of the user, eg. while offboarding the user.
+// keys are deleted one by one so that failure to delete a key does not
+// stop deletion of the other. result is provided for every key the user
+// had, keyed by its slot 1 or 2, along with an error joining the errors
+// of the keys which could not be deleted. user without any keys is not an
+// error
+func (c *objectUserClient) RevokeAllSecretKeys(userID, namespace string) ([]client.BulkResult[int], error) {
+	keys, err := c.ListSecretKeys(userID, namespace)
+	if err != nil {
+		return nil, err
+	}
+	var results []client.BulkResult[int]
+	for i, key := range []string{keys.SecretKey1, keys.SecretKey2} {
+		if key == "" {
+			continue
+		}
+		err = c.DeleteSecretKey(userID, &DeleteSecretKeyReq{
+			Namespace: namespace,
+			SecretKey: key,
+		})
+		if err != nil {
+			err = fmt.Errorf("failed to delete secret key %d of user %s: %w", i+1, userID, err)
+		}
+		results = append(results, client.BulkResult[int]{Item: i + 1, Err: err})
+	}
+	return results, client.BulkError(results)
+}
+
+// provides the tags of object user, fails with ErrNotFound if the user
+// does not exist. tags are supported only by newer versions of ECS
+func (c *objectUserClient) GetObjectUserTags(userID, namespace string) (map[string]string, error) {
+	var query url.Values
+	if namespace != "" {
+		query = url.Values{}
+		query.Add("namespace", namespace)
+	}
+	bytes, err := c.apiClient.Get("/object/users/"+userID+"/tags", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ObjectUserTagsResp{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get object user tags", err)
+		return nil, err
+	}
+	tags := map[string]string{}
+	for _, tag := range resp.Tags {
+		tags[tag.Key] = tag.Value
+	}
+	return tags, nil
+}
+
+// replaces the tags of object user with the given tags, an empty map
+// removes all the tags
+func (c *objectUserClient) SetObjectUserTags(userID, namespace string, tags map[string]string) error {
+	req := &ObjectUserTagsReq{
+		Namespace: namespace,
+		Tags:      []Tag{},
+	}
+	for k, v := range tags {
+		if k == "" || len(k) > MaxTagKeyLength {
+			return ecserrors.Wrap(fmt.Sprintf("tag key %q must be 1 to %d characters", k, MaxTagKeyLength))
+		}
+		if len(v) > MaxTagValueLength {
+			return ecserrors.Wrap(fmt.Sprintf("value of tag %q exceeds %d characters", k, MaxTagValueLength))
+		}
+		req.Tags = append(req.Tags, Tag{Key: k, Value: v})
+	}
+	// keeps the request deterministic
+	sort.Slice(req.Tags, func(i, j int) bool { return req.Tags[i].Key < req.Tags[j].Key })
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/object/users/"+userID+"/tags", data, nil, nil)
+	return err
+}
+
+func (c *objectUserClient) GetObjectUser(userID, namespace string) (*ObjectUser, error) {
+	var query url.Values
+	if namespace != "" {
+		query = url.Values{}
+		query.Add("namespace", namespace)
+	}
+	bytes, err := c.apiClient.Get("/object/users/"+userID+"/info", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ObjectUser{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get object user", err)
+		return resp, err
+	}
+	resp.CreatedTime = parseTimestamp(resp.Created)
+	return resp, nil
+}
+
+// lists the users of namespace created before olderThan which also have
+// no secret key created since then. ECS does not track last use of a
+// user, so creation of the user and its keys is the only activity known.
+// users whose timestamps are not reported in a known format are not
+// considered dormant. failure to fetch a user does not stop the listing,
+// the returned error covers all the users which could not be checked
+func (c *objectUserClient) ListDormantUsers(namespace string, olderThan time.Time) ([]ObjectUser, error) {
+	var dormant []ObjectUser
+	var errs []error
+	param := &ObjectUserListParameters{Namespace: namespace}
+	for {
+		users, err := c.GetList(param)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users.Users {
+			user, err := c.GetObjectUser(u.UserID, namespace)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to get user %s: %w", u.UserID, err))
+				continue
+			}
+			if user.CreatedTime.IsZero() || !user.CreatedTime.Before(olderThan) {
+				continue
+			}
+			keys, err := c.ListSecretKeys(u.UserID, namespace)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to list secret keys of user %s: %w", u.UserID, err))
+				continue
+			}
+			active := false
+			for _, ts := range []string{keys.KeyTimestamp1, keys.KeyTimestamp2} {
+				if ts == "" {
+					continue
+				}
+				t := parseTimestamp(ts)
+				if t.IsZero() || !t.Before(olderThan) {
+					active = true
+				}
+			}
+			if !active {
+				dormant = append(dormant, *user)
+			}
+		}
+		if users.NextMarker == "" || users.NextMarker == param.Marker {
+			break
+		}
+		param.Marker = users.NextMarker
+	}
+	return dormant, errors.Join(errs...)
+}
+
+// sets the password of object user used by swift and other password
+// based object apis, replacing the existing one. the password is
+// redacted in dry run previews and debug dumps and is never logged
+func (c *objectUserClient) SetObjectUserPassword(userID, namespace, password string) error {
+	if password == "" {
+		return ecserrors.Wrap("password is required")
+	}
+	data, err := json.Marshal(&objectUserPasswordReq{
+		Namespace: namespace,
+		Password:  password,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/object/user-password/"+userID, data, nil, nil)
+	return err
+}
+
+// removes the password of object user, password based authentication of
+// the user fails afterwards
+func (c *objectUserClient) DeleteObjectUserPassword(userID, namespace string) error {
+	data, err := json.Marshal(&objectUserPasswordDeleteReq{Namespace: namespace})
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Post("/object/user-password/"+userID+"/deactivate", data, nil, nil)
+	return err
+}
+
+// provides a record for every secret key of the users of namespace, for
+// reporting keys past the rotation policy. keys of upto
+// inventoryConcurrency users are fetched in parallel. records are sorted
+// by issue time with the oldest first, keys whose issue time could not be
+// parsed are at the end. failure to list keys of a user does not stop the
+// inventory, the returned error covers all such users
+func (c *objectUserClient) SecretKeyInventory(ctx context.Context, namespace string) ([]KeyRecord, error) {
+	var userIDs []string
+	param := &ObjectUserListParameters{Namespace: namespace}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		users, err := c.GetList(param)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users.Users {
+			userIDs = append(userIDs, u.UserID)
+		}
+		if users.NextMarker == "" || users.NextMarker == param.Marker {
+			break
+		}
+		param.Marker = users.NextMarker
+	}
+
+	var mu sync.Mutex
+	var records []KeyRecord
+	var errs []error
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, inventoryConcurrency)
+	for _, userID := range userIDs {
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("secret keys of user %s not listed: %w", userID, ctx.Err()))
+			mu.Unlock()
+			continue
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(userID string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			keys, err := c.ListSecretKeys(userID, namespace)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to list secret keys of user %s: %w", userID, err))
+				return
+			}
+			records = append(records, keyRecords(userID, keys)...)
+		}(userID)
+	}
+	wg.Wait()
+
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := records[i].Issued, records[j].Issued
+		if a.IsZero() || b.IsZero() {
+			return !a.IsZero() && b.IsZero()
+		}
+		return a.Before(b)
+	})
+	return records, errors.Join(errs...)
+}
+
+// records of the keys present in the slots of the user
+func keyRecords(userID string, keys *SecretKeysResp) []KeyRecord {
+	var records []KeyRecord
+	slots := []struct {
+		key, issued, expiry string
+		exist               bool
+	}{
+		{keys.SecretKey1, keys.KeyTimestamp1, keys.KeyExpiryTimestamp1, keys.SecretKey1Exist},
+		{keys.SecretKey2, keys.KeyTimestamp2, keys.KeyExpiryTimestamp2, keys.SecretKey2Exist},
+	}
+	for i, slot := range slots {
+		if slot.key == "" && !slot.exist {
+			continue
+		}
+		records = append(records, KeyRecord{
+			UserID: userID,
+			Slot:   i + 1,
+			Issued: parseTimestamp(slot.issued),
+			Expiry: parseTimestamp(slot.expiry),
+		})
+	}
+	return records
+}
+
+// provides the quota of object user in GB, fails with ErrUnsupported on
+// ECS versions not supporting user quota or if the client passed to
+// GetEcsObjectUserClient does not provide the version of ECS
+func (c *objectUserClient) GetObjectUserQuota(userID, namespace string) (*UserQuota, error) {
+	if err := client.RequireVersion(c.apiClient, minUserQuotaVersion); err != nil {
+		return nil, err
+	}
+	var query url.Values
+	if namespace != "" {
+		query = url.Values{}
+		query.Add("namespace", namespace)
+	}
+	bytes, err := c.apiClient.Get("/object/users/"+userID+"/quota", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &userQuotaResp{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get object user quota", err)
+		return nil, err
+	}
+	return &resp.UserQuota, nil
+}
+
+// sets the quota of object user in GB, a limit of QuotaUnlimited disables
+// it. fails with ErrUnsupported on ECS versions not supporting user quota
+func (c *objectUserClient) SetObjectUserQuota(userID, namespace string, quota UserQuota) error {
+	if quota.BlockSize < QuotaUnlimited || quota.NotificationSize < QuotaUnlimited {
+		return ecserrors.Wrap("user quota limits cannot be less than QuotaUnlimited")
+	}
+	if err := client.RequireVersion(c.apiClient, minUserQuotaVersion); err != nil {
+		return err
+	}
+	data, err := json.Marshal(&userQuotaResp{
+		UserQuota: quota,
+		Namespace: namespace,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/object/users/"+userID+"/quota", data, nil, nil)
+	return err
+}
+
+// zero time if ts is not in any of the known layouts
+func parseTimestamp(ts string) time.Time {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, ts); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// provides EcsObjectUserClient for give handler to EcsClient
+func GetEcsObjectUserClient(apiClient client.Session, opts ...Option) ObjectUserClient {
+	c := &objectUserClient{
+		apiClient: apiClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
@@ -0,0 +1,141 @@
+package objectuser
+
+import (
+	"time"
+
+	client "github.com/coredgeio/goecsclient"
+)
+
+type Link struct {
+	Rel  string `json:"rel,omitempty"`
+	Href string `json:"href,omitempty"`
+}
+
+type ObjectUserListParameters struct {
+	// Namespace for which object users should be listed.
+	Namespace string
+
+	// reference to last object returned and number of objects requested
+	// in current fetch
+	client.ListOptions
+}
+
+type ObjectUserListResp struct {
+	Users []struct {
+		UserID    string `json:"userid,omitempty"`
+		Namespace string `json:"namespace,omitempty"`
+	} `json:"blobuser,omitempty"`
+	Filter     string `json:"Filter,omitempty"`
+	MaxUsers   int    `json:"MaxUsers,omitempty"`
+	NextMarker string `json:"NextMarker,omitempty"`
+}
+
+// details of an object user. ECS provides only the creation time of the
+// user and of its secret keys, last use of the user or its keys is not
+// tracked
+type ObjectUser struct {
+	UserID    string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Locked    bool   `json:"locked,omitempty"`
+	// creation time as reported by ECS, eg. Tue Jan 10 09:16:49 GMT 2023
+	Created string `json:"created,omitempty"`
+	Tags    []Tag  `json:"tag,omitempty"`
+
+	// Created parsed, zero if not reported in a known format
+	CreatedTime time.Time `json:"-"`
+}
+
+// secret keys of an object user, ECS allows at most two keys per user
+type SecretKeysResp struct {
+	SecretKey1          string `json:"secret_key_1,omitempty"`
+	KeyTimestamp1       string `json:"key_timestamp_1,omitempty"`
+	KeyExpiryTimestamp1 string `json:"key_expiry_timestamp_1,omitempty"`
+	SecretKey1Exist     bool   `json:"secret_key_1_exist,omitempty"`
+	SecretKey2          string `json:"secret_key_2,omitempty"`
+	KeyTimestamp2       string `json:"key_timestamp_2,omitempty"`
+	KeyExpiryTimestamp2 string `json:"key_expiry_timestamp_2,omitempty"`
+	SecretKey2Exist     bool   `json:"secret_key_2_exist,omitempty"`
+	Link                Link   `json:"link,omitempty"`
+}
+
+// QuotaUnlimited disables the corresponding quota limit
+const QuotaUnlimited = int64(-1)
+
+// quota of object user in GB, same as of bucket BlockSize is the hard
+// quota beyond which writes by the user are blocked and NotificationSize
+// is the soft quota beyond which ECS raises a quota alert
+type UserQuota struct {
+	BlockSize        int64 `json:"blockSize"`
+	NotificationSize int64 `json:"notificationSize"`
+}
+
+type userQuotaResp struct {
+	UserQuota
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// secret key of an object user as provided by SecretKeyInventory, the
+// key itself is not included
+type KeyRecord struct {
+	UserID string
+	// slot of the key, 1 or 2
+	Slot int
+	// zero if not reported in a known format
+	Issued time.Time
+	// zero if the key does not expire
+	Expiry time.Time
+}
+
+type CreateSecretKeyReq struct {
+	Namespace string `json:"namespace,omitempty"`
+	// secret key to be used, ECS generates one if not provided
+	SecretKey string `json:"secretkey,omitempty"`
+	// minutes after which the existing key of the user expires, giving
+	// applications a grace period to move to the new key. zero leaves the
+	// expiry of existing key to ECS defaults
+	ExistingKeyExpiryMins int `json:"existing_key_expiry_time_mins,omitempty"`
+}
+
+type CreateSecretKeyResp struct {
+	SecretKey    string `json:"secret_key,omitempty"`
+	KeyTimestamp string `json:"key_timestamp,omitempty"`
+	// expiry of the previously existing key, if one was set
+	KeyExpiryTimestamp string `json:"key_expiry_timestamp,omitempty"`
+	Link               Link   `json:"link,omitempty"`
+}
+
+type DeleteSecretKeyReq struct {
+	Namespace string `json:"namespace,omitempty"`
+	// secret key to be deleted, all the keys of the user are deleted if
+	// not provided
+	SecretKey string `json:"secret_key,omitempty"`
+}
+
+const (
+	// max length of object user tag key and value accepted by ECS
+	MaxTagKeyLength   = 128
+	MaxTagValueLength = 256
+)
+
+type Tag struct {
+	Key   string `json:"Key"`
+	Value string `json:"Value"`
+}
+
+type ObjectUserTagsResp struct {
+	Tags []Tag `json:"tags,omitempty"`
+}
+
+type ObjectUserTagsReq struct {
+	Namespace string `json:"namespace,omitempty"`
+	Tags      []Tag  `json:"tags"`
+}
+
+type objectUserPasswordReq struct {
+	Namespace string `json:"namespace,omitempty"`
+	Password  string `json:"password"`
+}
+
+type objectUserPasswordDeleteReq struct {
+	Namespace string `json:"namespace,omitempty"`
+}
@@ -0,0 +1,292 @@
+package goecsclient
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/coredgeio/goecsclient/errors"
+	"golang.org/x/net/proxy"
+)
+
+// Option allows configuring optional behaviour of the ecs client, options
+// are applied while the client is being created
+type Option func(*ecsSession)
+
+// enables retrying of requests that fail with network errors or with
+// errors which ECS reports as retryable, a request is attempted at most
+// maxRetries times in addition to the first attempt. POST requests other
+// than creates are not retried on network errors since they may have
+// been processed by ECS already
+func WithRetries(maxRetries int) Option {
+	return func(s *ecsSession) {
+		s.maxRetries = maxRetries
+	}
+}
+
+// caps the retries across all the requests of the client to a fraction
+// of the total requests made. every request adds ratio tokens to the
+// budget and every retry consumes a token, minTokens is the initial
+// balance allowing retries even when request rate is low.
+//
+// when the budget is exhausted the request fails with the last error
+// instead of being retried, this avoids retry storms overwhelming ECS
+// when it is already in trouble. budget has no effect unless retries are
+// enabled using WithRetries
+func WithRetryBudget(ratio float64, minTokens int) Option {
+	return func(s *ecsSession) {
+		s.retryBudget = newRetryBudget(ratio, minTokens)
+	}
+}
+
+// provides a preview of every api request to the callback before it is
+// sent, the preview carries the method, url, headers and body of the
+// request with auth token redacted. callback must not send the request.
+//
+// login requests are not previewed
+func WithDryRun(fn func(req *http.Request)) Option {
+	return func(s *ecsSession) {
+		s.dryRun = fn
+	}
+}
+
+// when enabled, api requests are not sent to ECS and every api call
+// returns an empty result, useful along with WithDryRun for reviewing
+// the requests a set of operations would make. raw requests made using
+// Do are previewed and fail with ErrPreviewOnly. login is still performed
+// to validate the credentials
+func WithPreviewOnly(enabled bool) Option {
+	return func(s *ecsSession) {
+		s.previewOnly = enabled
+	}
+}
+
+// sets the number of login attempts made for refreshing the token before
+// giving up, attempts are made with backoff in between. defaults to
+// DefaultRefreshAttempts
+func WithRefreshAttempts(attempts int) Option {
+	return func(s *ecsSession) {
+		if attempts > 0 {
+			s.refreshAttempts = attempts
+		}
+	}
+}
+
+// sets the callback invoked when token refresh is given up after all the
+// attempts, api requests start failing with auth errors once the token
+// expires. client needs to be recreated to recover from this
+func WithRefreshFailureHandler(fn func(err error)) Option {
+	return func(s *ecsSession) {
+		s.onRefreshFailure = fn
+	}
+}
+
+// enables or disables the background refresh of token, enabled by
+// default. when disabled no background goroutine is started and the
+// caller is responsible for calling Refresh before the token expires
+func WithAutoRefresh(enabled bool) Option {
+	return func(s *ecsSession) {
+		s.autoRefresh = enabled
+	}
+}
+
+// sets the endpoint of S3 compatible data api, eg. https://ecs-data:9021
+// used for object level operations. by default it is derived from the
+// management endpoint using the data port, see WithDataPort
+func WithDataEndpoint(endpoint string) Option {
+	return func(s *ecsSession) {
+		s.dataEndpoint = trimEndpoint(endpoint)
+	}
+}
+
+// overrides the port of the management endpoint, for deployments which
+// relocate the management api from DefaultManagementPort. without it the
+// port given in the endpoint is used as is
+func WithManagementPort(port int) Option {
+	return func(s *ecsSession) {
+		p, err := validatePort(port)
+		if err != nil {
+			s.setOptErr(err)
+			return
+		}
+		s.mgmtPort = p
+	}
+}
+
+// sets the port used to derive the data endpoint from the management
+// endpoint host, defaults to DefaultDataPort. not applicable when the data
+// endpoint is set using WithDataEndpoint
+func WithDataPort(port int) Option {
+	return func(s *ecsSession) {
+		p, err := validatePort(port)
+		if err != nil {
+			s.setOptErr(err)
+			return
+		}
+		s.dataPort = p
+	}
+}
+
+// writes the complete wire exchange of every api request and response to
+// w, with credentials redacted. meant for diagnosing issues with ECS, eg.
+// while filing support tickets, requests are not dumped when not set
+func WithDebugDump(w io.Writer) Option {
+	return func(s *ecsSession) {
+		if w == nil {
+			s.debugDump = nil
+			return
+		}
+		s.debugDump = &debugDumper{w: w}
+	}
+}
+
+// reports the timings of DNS lookup, connect, TLS handshake and time to
+// first byte of every request including login, to the callback. useful
+// for figuring out where the time goes on slow requests
+func WithClientTrace(fn func(info TraceInfo)) Option {
+	return func(s *ecsSession) {
+		s.clientTrace = fn
+	}
+}
+
+// sets the Accept-Language header on every request including login, eg.
+// "en-US" to get the error messages in english irrespective of the
+// locale of ECS. no Accept-Language is sent by default
+func WithAcceptLanguage(lang string) Option {
+	return func(s *ecsSession) {
+		s.acceptLanguage = lang
+	}
+}
+
+// sets the User-Agent header on every request including login, allowing
+// the requests of an application to be identified in ECS access logs.
+// defaults to DefaultUserAgent
+func WithUserAgent(userAgent string) Option {
+	return func(s *ecsSession) {
+		s.userAgent = userAgent
+	}
+}
+
+// overrides the header from which auth token is read in login response
+// and on which it is sent with every request, for gateways in front of
+// ECS which rename the header. header is sent with the name as given and
+// matched case insensitively in login response. defaults to
+// DefaultTokenHeader
+func WithTokenHeader(header string) Option {
+	return func(s *ecsSession) {
+		if header != "" {
+			s.tokenHeader = header
+		}
+	}
+}
+
+// limits the time taken by every request including reading the response
+// body, no limit by default. requests made using a session scoped with
+// EcsClient.WithContext are additionally bounded by the deadline of the
+// context, whichever is earlier applies. a timed out request is retried
+// as per WithRetries
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *ecsSession) {
+		if timeout < 0 {
+			s.setOptErr(errors.Wrap("timeout must not be negative"))
+			return
+		}
+		s.c.Timeout = timeout
+	}
+}
+
+// limits the size of response body read by the request methods and by
+// the data api clients, larger responses fail with ErrResponseTooLarge.
+// debug dumps leave out bodies beyond the limit. defaults to
+// DefaultMaxResponseBytes. bodies of responses returned by Do are read by
+// the caller and are not limited
+func WithMaxResponseBytes(max int64) Option {
+	return func(s *ecsSession) {
+		if max <= 0 {
+			s.setOptErr(errors.Wrap("max response bytes must be positive"))
+			return
+		}
+		s.maxResponseBytes = max
+	}
+}
+
+// controls following of redirects, same as CheckRedirect of http.Client,
+// eg. returning http.ErrUseLastResponse to not follow any. by default
+// upto 10 redirects are followed. auth token is set on the followed
+// requests irrespective of the host redirected to, fn can reject
+// redirects to untrusted hosts by returning an error
+func WithCheckRedirect(fn func(req *http.Request, via []*http.Request) error) Option {
+	return func(s *ecsSession) {
+		s.checkRedirect = fn
+	}
+}
+
+// sets the min tls version negotiated with ECS, one of the tls.VersionTLS*
+// constants. defaults to DefaultMinTLSVersion of tls 1.2, lower versions
+// are meant only for legacy systems
+func WithMinTLSVersion(version uint16) Option {
+	return func(s *ecsSession) {
+		switch version {
+		case tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13:
+			s.minTLSVersion = version
+		default:
+			s.setOptErr(errors.Wrap("invalid min tls version " + strconv.Itoa(int(version))))
+		}
+	}
+}
+
+// sets the clock used for scheduling token refresh along with its
+// retries and for TokenAge and TokenTTL, meant for tests driving the
+// refresh deterministically. defaults to the system clock
+func WithClock(clock Clock) Option {
+	return func(s *ecsSession) {
+		if clock != nil {
+			s.clock = clock
+		}
+	}
+}
+
+// disables reuse of connections, every request is sent on a new
+// connection with Connection: close. costs a connection setup per
+// request, but avoids intermittent EOF errors with intermediaries which
+// silently drop idle connections. keep alives are enabled by default
+func WithDisableKeepAlives(disable bool) Option {
+	return func(s *ecsSession) {
+		s.disableKeepAlives = disable
+	}
+}
+
+// allows customizing any setting of the http transport used for the api
+// requests, eg. dial timeouts or keep alives, when there is no specific
+// option for it. fn is invoked on the default transport after the
+// package defaults and the other options are applied, hence settings made
+// by fn take precedence over them. multiple funcs are invoked in order
+func WithTransportFunc(fn func(tr *http.Transport)) Option {
+	return func(s *ecsSession) {
+		if fn != nil {
+			s.transportFuncs = append(s.transportFuncs, fn)
+		}
+	}
+}
+
+// dials all the connections including login through the SOCKS5 proxy at
+// address, eg. a bastion reached over ssh tunnel. auth is nil for proxies
+// not requiring authentication. unlike an http proxy this works at tcp
+// level, so tls is still established end to end with ECS
+func WithSOCKS5Proxy(address string, auth *proxy.Auth) Option {
+	return func(s *ecsSession) {
+		dialer, err := proxy.SOCKS5("tcp", address, auth, proxy.Direct)
+		if err != nil {
+			s.setOptErr(err)
+			return
+		}
+		ctxDialer, ok := dialer.(proxy.ContextDialer)
+		if !ok {
+			s.setOptErr(errors.Wrap("socks5 dialer does not support context"))
+			return
+		}
+		s.dialContext = ctxDialer.DialContext
+	}
+}
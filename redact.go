@@ -0,0 +1,84 @@
+package goecsclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// headers carrying credentials, values of these are never to be logged
+var sensitiveHeaders = []string{
+	"X-SDS-AUTH-TOKEN",
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// fields of request and response bodies carrying credentials, redacted
+// in dry run previews and debug dumps
+var sensitiveFields = []string{
+	"password",
+	"root_user_password",
+	"current_root_user_password",
+	"new_root_user_password",
+	"secretkey",
+	"secret_key",
+	"secret_key_1",
+	"secret_key_2",
+}
+
+// provides the json body with values of credential carrying top level
+// fields redacted, body is returned as is if it is not a json object or
+// carries no credentials
+func redactBody(d []byte) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(d, &fields); err != nil {
+		return d
+	}
+	redacted := false
+	for k := range fields {
+		for _, f := range sensitiveFields {
+			if strings.EqualFold(k, f) {
+				fields[k] = json.RawMessage(`"` + redactedValue + `"`)
+				redacted = true
+			}
+		}
+	}
+	if !redacted {
+		return d
+	}
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return []byte(redactedValue)
+	}
+	return out
+}
+
+// provides copy of the headers with values of credential carrying headers
+// redacted, this must be used whenever headers of a request or response
+// are logged or handed out. extra lists additional headers to be redacted,
+// eg. custom token header. header names are matched case insensitively
+// since headers set directly on the map are not canonicalized
+func redactHeader(h http.Header, extra ...string) http.Header {
+	redacted := h.Clone()
+	for k := range redacted {
+		if isSensitiveHeader(k, extra) {
+			redacted[k] = []string{redactedValue}
+		}
+	}
+	return redacted
+}
+
+func isSensitiveHeader(name string, extra []string) bool {
+	for _, k := range sensitiveHeaders {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	for _, k := range extra {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package goecsclient
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testToken = "test-token-9f8e7d6c"
+
+// starts a fake ECS issuing testToken on login under the given header,
+// the token is echoed on every response to exercise response redaction
+func newTestServer(t *testing.T, tokenHeader string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(tokenHeader, testToken)
+		if r.URL.Path == "/login" {
+			w.Header().Set("X-SDS-AUTH-MAX-AGE", "3600")
+		}
+		w.Write([]byte("{}"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// safe for concurrent writes from the requests and log
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestTokenRedacted(t *testing.T) {
+	tests := []struct {
+		name        string
+		tokenHeader string
+		opts        []Option
+	}{
+		{name: "default header", tokenHeader: DefaultTokenHeader},
+		{name: "custom header", tokenHeader: "x-gateway-token", opts: []Option{WithTokenHeader("x-gateway-token")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := &syncBuffer{}
+			log.SetOutput(logs)
+			t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+			dump := &syncBuffer{}
+			preview := &syncBuffer{}
+			srv := newTestServer(t, tt.tokenHeader)
+			opts := append([]Option{
+				WithDebugDump(dump),
+				WithDryRun(func(req *http.Request) {
+					b, err := httputil.DumpRequestOut(req, true)
+					if err != nil {
+						t.Error(err)
+					}
+					preview.Write(b)
+				}),
+			}, tt.opts...)
+			c, err := CreateEcsClientWithUserCred("user", "pass", srv.URL, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+
+			if _, err := c.Get("/object/bucket", nil, nil); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.Post("/object/bucket", []byte("{}"), nil, nil); err != nil {
+				t.Fatal(err)
+			}
+			req, err := http.NewRequest("PUT", "/object/bucket/b1/tags", strings.NewReader("{}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := c.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			for name, out := range map[string]string{"log": logs.String(), "debug dump": dump.String(), "dry run": preview.String()} {
+				if strings.Contains(out, testToken) {
+					t.Errorf("token found in %s output:\n%s", name, out)
+				}
+			}
+			if !strings.Contains(dump.String(), redactedValue) {
+				t.Errorf("debug dump does not carry the redacted token header:\n%s", dump.String())
+			}
+			if !strings.Contains(preview.String(), redactedValue) {
+				t.Errorf("dry run does not carry the redacted token header:\n%s", preview.String())
+			}
+		})
+	}
+}
+
+func TestPasswordRedacted(t *testing.T) {
+	const password = "s3cr3t-passw0rd"
+	received := make(chan string, 1)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(DefaultTokenHeader, testToken)
+		if r.URL.Path != "/login" {
+			b, _ := io.ReadAll(r.Body)
+			received <- string(b)
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	dump := &syncBuffer{}
+	preview := &syncBuffer{}
+	c, err := CreateEcsClientWithUserCred("user", "pass", srv.URL,
+		WithDebugDump(dump),
+		WithDryRun(func(req *http.Request) {
+			b, err := httputil.DumpRequestOut(req, true)
+			if err != nil {
+				t.Error(err)
+			}
+			preview.Write(b)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	body := `{"namespace":"ns1","password":"` + password + `"}`
+	if _, err = c.Put("/object/user-password/u1", []byte(body), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-received; got != body {
+		t.Fatalf("expected body %s sent to ECS, got %s", body, got)
+	}
+	for name, out := range map[string]string{"debug dump": dump.String(), "dry run": preview.String()} {
+		if strings.Contains(out, password) {
+			t.Errorf("password found in %s output:\n%s", name, out)
+		}
+		if !strings.Contains(out, `"namespace":"ns1"`) {
+			t.Errorf("non sensitive fields missing in %s output:\n%s", name, out)
+		}
+	}
+}
+
+func TestSecretKeyRedactedInDump(t *testing.T) {
+	const secretKey = "wJalrXUtnFEMI-secret"
+	respBody := `{"secret_key_1":"` + secretKey + `","key_timestamp_1":"2024-01-01 00:00:00.000"}`
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(DefaultTokenHeader, testToken)
+		if r.URL.Path == "/login" {
+			w.Write([]byte("{}"))
+			return
+		}
+		w.Write([]byte(respBody))
+	}))
+	defer srv.Close()
+
+	dump := &syncBuffer{}
+	c, err := CreateEcsClientWithUserCred("user", "pass", srv.URL, WithDebugDump(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	got, err := c.Get("/object/user-secret-keys/u1", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != respBody {
+		t.Fatalf("expected response %s for the caller, got %s", respBody, got)
+	}
+	out := dump.String()
+	if strings.Contains(out, secretKey) {
+		t.Errorf("secret key found in debug dump:\n%s", out)
+	}
+	if !strings.Contains(out, `"key_timestamp_1"`) || !strings.Contains(out, redactedValue) {
+		t.Errorf("debug dump does not carry the redacted response body:\n%s", out)
+	}
+}
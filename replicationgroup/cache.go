@@ -0,0 +1,106 @@
+package replicationgroup
+
+import (
+	"sync"
+
+	client "github.com/coredgeio/goecsclient"
+	"github.com/coredgeio/goecsclient/errors"
+)
+
+// Cache keeps the list of replication groups available on the system to
+// avoid repeated list calls while resolving replication groups, eg.
+// during bulk creation of buckets. it is safe for concurrent use and can
+// be shared across clients.
+//
+// cache is loaded on first lookup and reloaded when a lookup misses, so
+// that newly created replication groups are found
+type Cache struct {
+	rgClient ReplicationGroupClient
+	mu       sync.Mutex
+	groups   []*ReplicationGroup
+}
+
+// returns the replication group with given id
+func (c *Cache) Lookup(id string) (*ReplicationGroup, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.groups != nil {
+		if rg := c.find(id); rg != nil {
+			return rg, nil
+		}
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	if rg := c.find(id); rg != nil {
+		return rg, nil
+	}
+	return nil, errors.ErrReplicationGroupNotFound
+}
+
+// returns the replication group with given id or name, id is matched
+// first. lets callers refer to replication groups by name, which is what
+// operators usually know them by
+func (c *Cache) Resolve(idOrName string) (*ReplicationGroup, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.groups != nil {
+		if rg := c.findByIDOrName(idOrName); rg != nil {
+			return rg, nil
+		}
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	if rg := c.findByIDOrName(idOrName); rg != nil {
+		return rg, nil
+	}
+	return nil, errors.ErrReplicationGroupNotFound
+}
+
+// drops the cached replication groups, next lookup reloads them
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.groups = nil
+}
+
+func (c *Cache) find(id string) *ReplicationGroup {
+	for _, rg := range c.groups {
+		if rg.ID == id {
+			return rg
+		}
+	}
+	return nil
+}
+
+func (c *Cache) findByIDOrName(idOrName string) *ReplicationGroup {
+	if rg := c.find(idOrName); rg != nil {
+		return rg
+	}
+	for _, rg := range c.groups {
+		if rg.Name == idOrName {
+			return rg
+		}
+	}
+	return nil
+}
+
+func (c *Cache) load() error {
+	resp, err := c.rgClient.GetList()
+	if err != nil {
+		return err
+	}
+	c.groups = resp.ReplicationGroups
+	if c.groups == nil {
+		c.groups = []*ReplicationGroup{}
+	}
+	return nil
+}
+
+// provides replication group Cache for given handler to EcsClient
+func NewCache(apiClient client.Session) *Cache {
+	return &Cache{
+		rgClient: GetEcsReplicationGroupClient(apiClient),
+	}
+}
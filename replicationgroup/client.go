@@ -0,0 +1,95 @@
+package replicationgroup
+
+import (
+	"encoding/json"
+	"log"
+
+	client "github.com/coredgeio/goecsclient"
+)
+
+type ReplicationGroupClient interface {
+	GetList() (*ReplicationGroupListResp, error)
+	Get(id string) (*ReplicationGroup, error)
+	SetNamespaces(id string, allowAll bool, namespaces []string) error
+	GetFailedZones() (*FailedZoneList, error)
+}
+
+type replicationGroupClient struct {
+	apiClient client.Session
+}
+
+func (c *replicationGroupClient) GetList() (*ReplicationGroupListResp, error) {
+	bytes, err := c.apiClient.Get("/vdc/data-service/vpools", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ReplicationGroupListResp{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get replication group list", err)
+	}
+	return resp, err
+}
+
+func (c *replicationGroupClient) Get(id string) (*ReplicationGroup, error) {
+	bytes, err := c.apiClient.Get("/vdc/data-service/vpools/"+id, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ReplicationGroup{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get replication group", err)
+	}
+	return resp, err
+}
+
+// sets the namespaces allowed to use the replication group, when allowAll
+// is set every namespace can use it and the list of namespaces is ignored.
+//
+// ECS expects name of the replication group as part of update, hence the
+// current name and description are read and sent along
+func (c *replicationGroupClient) SetNamespaces(id string, allowAll bool, namespaces []string) error {
+	rg, err := c.Get(id)
+	if err != nil {
+		return err
+	}
+	req := &ReplicationGroupUpdateReq{
+		Name:               rg.Name,
+		Description:        rg.Description,
+		AllowAllNamespaces: allowAll,
+		// sent even if empty, restricting to no namespaces
+		Namespaces: []string{},
+	}
+	if !allowAll && namespaces != nil {
+		req.Namespaces = namespaces
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = c.apiClient.Put("/vdc/data-service/vpools/"+id, data, nil, nil)
+	return err
+}
+
+// lists zones which are temporarily failed across all the replication
+// groups, empty list when all the zones are healthy
+func (c *replicationGroupClient) GetFailedZones() (*FailedZoneList, error) {
+	bytes, err := c.apiClient.Get("/tempfailedzone/allTempFailedZone", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &FailedZoneList{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get failed zones", err)
+	}
+	return resp, err
+}
+
+// provides EcsReplicationGroupClient for give handler to EcsClient
+func GetEcsReplicationGroupClient(apiClient client.Session) ReplicationGroupClient {
+	return &replicationGroupClient{
+		apiClient: apiClient,
+	}
+}
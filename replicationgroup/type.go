@@ -0,0 +1,53 @@
+package replicationgroup
+
+type ReplicationGroup struct {
+	ID                   string `json:"id,omitempty"`
+	Name                 string `json:"name,omitempty"`
+	Description          string `json:"description,omitempty"`
+	IsAllowAllNamespaces bool   `json:"isAllowAllNamespaces,omitempty"`
+	// namespaces allowed to use the replication group, applicable only if
+	// not all namespaces are allowed
+	Namespaces        []string `json:"namespaces,omitempty"`
+	EnableRebalancing bool     `json:"enable_rebalancing,omitempty"`
+	IsFullRep         bool     `json:"isFullRep,omitempty"`
+	VarrayMappings    []struct {
+		Name  string `json:"name,omitempty"`
+		Value string `json:"value,omitempty"`
+	} `json:"varrayMappings,omitempty"`
+	Link struct {
+		Rel  string `json:"rel,omitempty"`
+		Href string `json:"href,omitempty"`
+	} `json:"link,omitempty"`
+	CreationTime int64 `json:"creation_time,omitempty"`
+	Inactive     bool  `json:"inactive,omitempty"`
+	Global       bool  `json:"global,omitempty"`
+	Remote       bool  `json:"remote,omitempty"`
+	Internal     bool  `json:"internal,omitempty"`
+}
+
+type ReplicationGroupListResp struct {
+	ReplicationGroups []*ReplicationGroup `json:"data_service_vpool,omitempty"`
+}
+
+type ReplicationGroupUpdateReq struct {
+	Name               string   `json:"name,omitempty"`
+	Description        string   `json:"description,omitempty"`
+	AllowAllNamespaces bool     `json:"allowAllNamespaces"`
+	Namespaces         []string `json:"namespaces"`
+}
+
+// zone of a replication group which is temporarily failed (TSO), data
+// owned by the zone is served by the other zones till it recovers
+type FailedZone struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	// replication group in which the zone has failed
+	ReplicationGroup string `json:"replicationGroup,omitempty"`
+	// time at which the zone was detected as failed, in milliseconds
+	// since epoch
+	FailedTimestamp int64 `json:"failedTimestamp,omitempty"`
+}
+
+type FailedZoneList struct {
+	Zones []FailedZone `json:"tempfailedzone,omitempty"`
+}
@@ -0,0 +1,100 @@
+package goecsclient
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coredgeio/goecsclient/errors"
+)
+
+const (
+	retryBaseBackoff = 100 * time.Millisecond
+	retryMaxBackoff  = 5 * time.Second
+
+	// number of requests worth of tokens the retry budget can accumulate
+	// on top of its minimum balance
+	retryBudgetWindow = 100
+)
+
+// token bucket shared across the requests of a client for capping the
+// retries to a fraction of the requests
+type retryBudget struct {
+	mu        sync.Mutex
+	ratio     float64
+	maxTokens float64
+	tokens    float64
+}
+
+func newRetryBudget(ratio float64, minTokens int) *retryBudget {
+	return &retryBudget{
+		ratio:     ratio,
+		maxTokens: float64(minTokens) + ratio*retryBudgetWindow,
+		tokens:    float64(minTokens),
+	}
+}
+
+// credits the budget for a new request
+func (b *retryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += b.ratio
+	if b.tokens > b.maxTokens {
+		b.tokens = b.maxTokens
+	}
+}
+
+// consumes a token for a retry, returns false if budget is exhausted
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// network errors, errors marked retryable by ECS and errors resulting
+// from temporary unavailability of the server are retried
+func isRetryable(err error) bool {
+	e, ok := err.(*errors.Error)
+	if !ok {
+		return true
+	}
+	if e.Retryable {
+		return true
+	}
+	switch e.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// POST is not idempotent, unless the request is a create for which a
+// conflict on retry is handled, it is retried only when ECS responded
+// with a retryable error. a network error might have been received after
+// ECS processed the request, retrying it could repeat the operation, eg.
+// issuing another secret key
+func isRetryableRequest(method string, create bool, err error) bool {
+	if method == http.MethodPost && !create {
+		if _, ok := err.(*errors.Error); !ok {
+			return false
+		}
+	}
+	return isRetryable(err)
+}
+
+// exponential backoff between consecutive attempts of a request
+func retryBackoff(attempt int) time.Duration {
+	return backoff(attempt, retryBaseBackoff, retryMaxBackoff)
+}
+
+func backoff(attempt int, base, max time.Duration) time.Duration {
+	d := base << attempt
+	if d <= 0 || d > max {
+		return max
+	}
+	return d
+}
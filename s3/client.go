@@ -0,0 +1,151 @@
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	stderrors "errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	client "github.com/coredgeio/goecsclient"
+	"github.com/coredgeio/goecsclient/errors"
+)
+
+// S3Client performs object level operations against the S3 compatible
+// data api of ECS. requests are signed using the credentials of an object
+// user, the management api token is not used
+type S3Client interface {
+	ListObjects(ctx context.Context, accessKey, secretKey, bucket, prefix string) (<-chan ObjectInfo, <-chan error)
+	VerifySecretKey(ctx context.Context, accessKey, secretKey string) error
+	GetObjectRetention(ctx context.Context, accessKey, secretKey, bucket, object string) (*ObjectRetention, error)
+}
+
+type s3Client struct {
+	apiClient client.DataSession
+}
+
+// streams all the objects of the bucket having the given prefix, pages
+// are fetched as the objects are consumed. objects channel is closed once
+// all the objects are listed, listing stops upon failure or context
+// cancellation with the error delivered on error channel
+func (c *s3Client) ListObjects(ctx context.Context, accessKey, secretKey, bucket, prefix string) (<-chan ObjectInfo, <-chan error) {
+	objects := make(chan ObjectInfo)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(objects)
+		defer close(errs)
+		token := ""
+		for {
+			q := url.Values{}
+			q.Set("list-type", "2")
+			if prefix != "" {
+				q.Set("prefix", prefix)
+			}
+			if token != "" {
+				q.Set("continuation-token", token)
+			}
+			resp := &listObjectsV2Resp{}
+			if err := c.get(ctx, accessKey, secretKey, "/"+bucket, q, resp); err != nil {
+				errs <- err
+				return
+			}
+			for _, obj := range resp.Contents {
+				select {
+				case objects <- *obj:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			}
+			if !resp.IsTruncated || resp.NextContinuationToken == "" {
+				return
+			}
+			token = resp.NextContinuationToken
+		}
+	}()
+	return objects, errs
+}
+
+// verifies the secret key authenticates against the data api by listing
+// buckets of the user, which has no side effects. newly issued keys may
+// take a while to propagate across a geo federation, the check can be
+// repeated till it succeeds
+func (c *s3Client) VerifySecretKey(ctx context.Context, accessKey, secretKey string) error {
+	return c.get(ctx, accessKey, secretKey, "/", nil, nil)
+}
+
+// provides the retention of an object of the bucket with object lock
+// enabled, fails with ErrNotFound if the object does not exist. object
+// without retention has an empty mode and zero retain until date
+func (c *s3Client) GetObjectRetention(ctx context.Context, accessKey, secretKey, bucket, object string) (*ObjectRetention, error) {
+	q := url.Values{}
+	q.Set("retention", "")
+	resp := &ObjectRetention{}
+	err := c.get(ctx, accessKey, secretKey, "/"+bucket+"/"+object, q, resp)
+	if err != nil {
+		var e *errors.Error
+		if stderrors.As(err, &e) && strings.Contains(e.Msg, "NoSuchObjectLockConfiguration") {
+			return &ObjectRetention{}, nil
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// performs signed GET request against the data endpoint, decoding the
+// xml response into resp
+func (c *s3Client) get(ctx context.Context, accessKey, secretKey, path string, q url.Values, resp interface{}) error {
+	u, err := url.Parse(c.apiClient.DataEndpoint())
+	if err != nil {
+		return err
+	}
+	// path is set unescaped, object keys may carry characters like ? or
+	// #. escaped form on the wire is kept same as the one signed
+	u.Path += path
+	u.RawPath = uriEncode(u.Path, false)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	if q != nil {
+		req.URL.RawQuery = q.Encode()
+	}
+	signRequest(req, accessKey, secretKey, time.Now())
+	r, err := c.apiClient.HTTPClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	body, err := c.apiClient.ReadBody(r.Body)
+	if err != nil {
+		return err
+	}
+	if r.StatusCode != http.StatusOK {
+		return parseError(r, body)
+	}
+	if resp == nil {
+		return nil
+	}
+	return xml.Unmarshal(body, resp)
+}
+
+// S3 errors are xml encoded, unlike the management api
+func parseError(r *http.Response, body []byte) error {
+	e := &struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}{}
+	if err := xml.Unmarshal(body, e); err != nil || e.Message == "" {
+		return errors.ParseHttpError(r.StatusCode, r.Status, nil)
+	}
+	return errors.ParseHttpError(r.StatusCode, e.Code+": "+e.Message, nil)
+}
+
+// provides EcsS3Client for give handler to EcsClient
+func GetEcsS3Client(apiClient client.DataSession) S3Client {
+	return &s3Client{
+		apiClient: apiClient,
+	}
+}
@@ -2,12 +2,21 @@ package goecsclient
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
 	"io"
 	"log"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/coredgeio/goecsclient/errors"
@@ -19,103 +28,389 @@ type ecsSession struct {
 	Endpoint string
 	Token    string
 	c        *http.Client
+	// transport of the http client, allowing tls config to be reloaded
+	transport *reloadableTransport
+
+	// endpoint of the S3 compatible data api
+	dataEndpoint string
+	// when set, data endpoint is derived from the management endpoint
+	// and follows it when changed using SetEndpoint
+	dataEndpointDerived bool
+	// port overriding the one of management endpoint, empty if not set
+	mgmtPort string
+	// port of data api used to derive the data endpoint
+	dataPort string
+
+	// protects the token which gets updated by refresh, along with the
+	// endpoints which can be changed using SetEndpoint
+	mu sync.RWMutex
+	// time at which current token was obtained
+	tokenIssuedAt time.Time
+	// max age of current token, zero if not known
+	tokenMaxAge time.Duration
+	// clock for token age and refresh scheduling
+	clock Clock
+	// serializes login attempts, avoiding manual and background refresh
+	// racing with each other
+	loginMu sync.Mutex
+	// notifies background refresh about the token age of manual refresh
+	refreshed chan int64
+	// cancelled when session is closed, stopping the token refresh
+	ctx    context.Context
+	cancel context.CancelFunc
+	// number of login attempts made for every token refresh
+	refreshAttempts int
+	// invoked when token refresh is given up
+	onRefreshFailure func(err error)
+	// when disabled, token is not refreshed in background and caller is
+	// responsible for refreshing it using Refresh
+	autoRefresh bool
+
+	// max number of retries for a failing request, zero disables retries
+	maxRetries int
+	// shared budget capping the retries across all requests, nil when
+	// retries are not limited by budget
+	retryBudget *retryBudget
+
+	// callback receiving preview of every api request
+	dryRun func(req *http.Request)
+	// when set api requests are only previewed and not sent to ECS
+	previewOnly bool
+	// writes wire dump of every api request and response, nil when
+	// disabled
+	debugDump *debugDumper
+	// callback receiving phase timings of every request
+	clientTrace func(info TraceInfo)
+	// Accept-Language sent on every request, none if empty
+	acceptLanguage string
+	// User-Agent sent on every request
+	userAgent string
+	// header carrying the auth token in login response and requests
+	tokenHeader string
+	// customizations applied on the default transport
+	transportFuncs []func(tr *http.Transport)
+	// min tls version negotiated with ECS
+	minTLSVersion uint16
+	// when set, connections are not reused across requests
+	disableKeepAlives bool
+	// dialer of the transport, nil for direct connections
+	dialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	// guards closed against requests starting while shutting down
+	closeMu sync.RWMutex
+	closed  bool
+	// requests in flight, drained on shutdown
+	inflight sync.WaitGroup
+
+	// redirect policy set using WithCheckRedirect, nil for default
+	checkRedirect func(req *http.Request, via []*http.Request) error
+	// max size of response body read, larger responses are rejected
+	maxResponseBytes int64
+
+	// first invalid option, reported on creating the session
+	optErr error
+
+	// cached ECS version, fetched on first use
+	versionMu  sync.Mutex
+	ecsVersion *EcsVersion
 }
 
 const (
 	TimeBufferInSeconds = int64(300)
+
+	// header carrying the auth token, unless overridden using
+	// WithTokenHeader
+	DefaultTokenHeader = "X-SDS-AUTH-TOKEN"
+
+	// max size of response body read unless overridden using
+	// WithMaxResponseBytes, generous for the management api responses
+	DefaultMaxResponseBytes = int64(64 << 20)
+
+	// min tls version unless overridden using WithMinTLSVersion
+	DefaultMinTLSVersion = tls.VersionTLS12
+
+	// default number of login attempts made for every token refresh
+	DefaultRefreshAttempts = 5
+
+	// redirects followed by default, same as http.Client
+	maxRedirects = 10
+
+	refreshBaseBackoff = time.Second
+	refreshMaxBackoff  = time.Minute
 )
 
 func (s *ecsSession) Get(subUrl string, q url.Values, headers map[string]string) ([]byte, error) {
-	req, err := http.NewRequest("GET", s.Endpoint+subUrl, nil)
-	if q != nil {
-		req.URL.RawQuery = q.Encode()
+	resp, err := s.doRequest(context.Background(), "GET", subUrl, nil, q, headers, false)
+	if err != nil {
+		return nil, err
 	}
-	req.Header.Set("X-SDS-AUTH-TOKEN", s.Token)
-	req.Header.Set("Accept", "application/json")
+	return resp.Body, nil
+}
 
-	for k, v := range headers {
-		req.Header.Set(k, v)
+func (s *ecsSession) Post(subUrl string, d []byte, q url.Values, headers map[string]string) ([]byte, error) {
+	resp, err := s.doRequest(context.Background(), "POST", subUrl, d, q, headers, false)
+	if err != nil {
+		return nil, err
 	}
-	
-	resp, err := s.c.Do(req)
+	return resp.Body, nil
+}
+
+func (s *ecsSession) PostWithResponse(subUrl string, d []byte, q url.Values, headers map[string]string) (*Response, error) {
+	return s.doRequest(context.Background(), "POST", subUrl, d, q, headers, false)
+}
+
+// posts a create request, see EcsClient.Create
+func (s *ecsSession) Create(subUrl string, d []byte, q url.Values, headers map[string]string) (*Response, error) {
+	return s.doRequest(context.Background(), "POST", subUrl, d, q, headers, true)
+}
+
+func (s *ecsSession) Put(subUrl string, d []byte, q url.Values, headers map[string]string) ([]byte, error) {
+	resp, err := s.doRequest(context.Background(), "PUT", subUrl, d, q, headers, false)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
-	defer func() {
-		if resp.Body != nil {
-			resp.Body.Close()
+	return resp.Body, nil
+}
+
+// posts multipart/form-data body built from fields and files, keyed by
+// form field name. files are sent with the field name as file name
+func (s *ecsSession) PostMultipart(subUrl string, fields map[string]string, files map[string]io.Reader) ([]byte, error) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	// sorted for the body to be deterministic
+	for _, k := range sortedKeys(fields) {
+		if err := w.WriteField(k, fields[k]); err != nil {
+			return nil, err
 		}
-	}()
-	var bodyBytes []byte
-	if resp.Body != nil {
-		bodyBytes, err = io.ReadAll(resp.Body)
+	}
+	for _, k := range sortedKeys(files) {
+		part, err := w.CreateFormFile(k, k)
 		if err != nil {
-			log.Println("failed to read Body", err)
 			return nil, err
 		}
-	}
-	if resp.StatusCode != http.StatusOK {
-		if bodyBytes != nil {
-			return nil, errors.ParseError(bodyBytes)
+		if _, err = io.Copy(part, files[k]); err != nil {
+			return nil, err
 		}
-		return nil, errors.Wrap(resp.Status)
 	}
-	return bodyBytes, nil
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	resp, err := s.doRequest(context.Background(), "POST", subUrl, body.Bytes(), nil, map[string]string{
+		"Content-Type": w.FormDataContentType(),
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
 }
 
-func (s *ecsSession) Post(subUrl string, d []byte, q url.Values, headers map[string]string) ([]byte, error) {
-	req, _ := http.NewRequest("POST", s.Endpoint+subUrl, bytes.NewReader(d))
-	if q != nil {
-		req.URL.RawQuery = q.Encode()
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
 	}
-	req.Header.Set("X-SDS-AUTH-TOKEN", s.Token)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	
-	for k, v := range headers {
-		req.Header.Set(k, v)
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *ecsSession) Delete(subUrl string, q url.Values, headers map[string]string) ([]byte, error) {
+	resp, err := s.doRequest(context.Background(), "DELETE", subUrl, nil, q, headers, false)
+	if err != nil {
+		return nil, err
 	}
-	resp, err := s.c.Do(req)
+	return resp.Body, nil
+}
+
+// sends the request as is after setting the auth token, the response is
+// returned without being read or closed and non success status codes are
+// not converted to errors. caller is responsible for closing the body.
+//
+// a request url without host is resolved relative to the endpoint. dry
+// run, preview only, debug dump and client trace apply the same as for
+// other requests, traced timings end once the response headers arrive
+// since the body is read by the caller
+func (s *ecsSession) Do(req *http.Request) (*http.Response, error) {
+	done, err := s.startRequest()
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
-	defer func() {
-		if resp.Body != nil {
-			resp.Body.Close()
-		}
-	}()
-	var bodyBytes []byte
-	if resp.Body != nil {
-		bodyBytes, err = io.ReadAll(resp.Body)
+	// response body is read by the caller after return, which is not
+	// tracked as in flight
+	defer done()
+	if req.URL.Host == "" {
+		base, err := url.Parse(s.getEndpoint())
 		if err != nil {
-			log.Println("failed to read Body", err)
 			return nil, err
 		}
+		req.URL = base.ResolveReference(&url.URL{
+			Path:     strings.TrimRight(base.Path, "/") + req.URL.Path,
+			RawQuery: req.URL.RawQuery,
+		})
+		req.Host = ""
+	}
+	s.setCommonHeaders(req)
+	s.setTokenHeader(req)
+
+	if s.dryRun != nil || s.debugDump != nil {
+		// body is needed for preview and dump, hence read upfront and
+		// restored on the request
+		var d []byte
+		if req.Body != nil && req.Body != http.NoBody {
+			var err error
+			d, err = io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = io.NopCloser(bytes.NewReader(d))
+		}
+		if s.dryRun != nil {
+			s.dryRun(previewRequest(req, d, s.tokenHeader))
+		}
+		if s.debugDump != nil && !s.previewOnly {
+			s.debugDump.dumpRequest(req, d, s.tokenHeader)
+		}
+	}
+	if s.previewOnly {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, errors.ErrPreviewOnly
+	}
+
+	req, traceDone := s.traceRequest(req)
+	resp, err := s.c.Do(req)
+	traceDone(err)
+	if err != nil {
+		return nil, err
+	}
+	if s.debugDump != nil {
+		s.debugDump.dumpResponse(resp, s.tokenHeader, s.maxResponseBytes)
 	}
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		if bodyBytes != nil {
-			return nil, errors.ParseError(bodyBytes)
+	return resp, nil
+}
+
+// performs GET on any api endpoint, returning the undecoded json response
+func (s *ecsSession) RawGet(subUrl string, q url.Values) (json.RawMessage, error) {
+	bytes, err := s.Get(subUrl, q, nil)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(bytes), nil
+}
+
+// performs POST on any api endpoint, returning the undecoded json
+// response
+func (s *ecsSession) RawPost(subUrl string, d []byte, q url.Values) (json.RawMessage, error) {
+	bytes, err := s.Post(subUrl, d, q, nil)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(bytes), nil
+}
+
+func (s *ecsSession) DataEndpoint() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dataEndpoint
+}
+
+func (s *ecsSession) HTTPClient() *http.Client {
+	return s.c
+}
+
+// reads the body of a response received using HTTPClient, see
+// DataSession.ReadBody
+func (s *ecsSession) ReadBody(r io.Reader) ([]byte, error) {
+	return s.readBody(r)
+}
+
+// sets the headers configured to be sent on every request
+func (s *ecsSession) setCommonHeaders(req *http.Request) {
+	req.Header.Set("User-Agent", s.userAgent)
+	if s.acceptLanguage != "" {
+		req.Header.Set("Accept-Language", s.acceptLanguage)
+	}
+}
+
+// performs the api request, retrying it as per configured retry policy
+// if it fails with a retryable error
+// create marks the request as creating a resource, a conflict received on
+// retrying such a request fails with ErrCreatedByEarlierAttempt since the
+// conflict is likely caused by the previous attempt having created the
+// resource before its response was lost
+func (s *ecsSession) doRequest(ctx context.Context, method, subUrl string, d []byte, q url.Values, headers map[string]string, create bool) (*Response, error) {
+	done, err := s.startRequest()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	if s.retryBudget != nil {
+		s.retryBudget.deposit()
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := s.doRequestOnce(ctx, method, subUrl, d, q, headers)
+		if create && attempt > 0 && stderrors.Is(err, errors.ErrAlreadyExists) {
+			log.Println("conflict on retried create, resource likely created by previous attempt", subUrl)
+			return nil, fmt.Errorf("%w: %w", errors.ErrCreatedByEarlierAttempt, err)
+		}
+		if err == nil || attempt >= s.maxRetries || ctx.Err() != nil || !isRetryableRequest(method, create, err) {
+			return resp, err
+		}
+		if s.retryBudget != nil && !s.retryBudget.withdraw() {
+			log.Println("retry budget exhausted, not retrying", method, subUrl)
+			return nil, err
+		}
+		select {
+		case <-time.After(retryBackoff(attempt)):
+		case <-ctx.Done():
+			return nil, err
 		}
-		return nil, errors.Wrap(resp.Status)
 	}
-	return bodyBytes, nil
 }
 
-func (s *ecsSession) Put(subUrl string, d []byte, q url.Values) ([]byte, error) {
-	req, _ := http.NewRequest("PUT", s.Endpoint+subUrl, bytes.NewReader(d))
+func (s *ecsSession) doRequestOnce(ctx context.Context, method, subUrl string, d []byte, q url.Values, headers map[string]string) (*Response, error) {
+	var body io.Reader
+	if d != nil {
+		body = bytes.NewReader(d)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.getEndpoint()+subUrl, body)
+	if err != nil {
+		return nil, err
+	}
 	if q != nil {
 		req.URL.RawQuery = q.Encode()
 	}
-	req.Header.Set("X-SDS-AUTH-TOKEN", s.Token)
-	req.Header.Set("Content-Type", "application/json")
+	s.setCommonHeaders(req)
+	s.setTokenHeader(req)
+	if method != "GET" {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	req.Header.Set("Accept", "application/json")
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	if s.dryRun != nil {
+		s.dryRun(previewRequest(req, d, s.tokenHeader))
+	}
+	if s.previewOnly {
+		return &Response{Body: []byte("{}")}, nil
+	}
+
+	if s.debugDump != nil {
+		s.debugDump.dumpRequest(req, d, s.tokenHeader)
+	}
+	req, traceDone := s.traceRequest(req)
 	resp, err := s.c.Do(req)
 	if err != nil {
+		traceDone(err)
 		log.Println(err)
 		return nil, err
 	}
+	if s.debugDump != nil {
+		s.debugDump.dumpResponse(resp, s.tokenHeader, s.maxResponseBytes)
+	}
 	defer func() {
 		if resp.Body != nil {
 			resp.Body.Close()
@@ -123,33 +418,70 @@ func (s *ecsSession) Put(subUrl string, d []byte, q url.Values) ([]byte, error)
 	}()
 	var bodyBytes []byte
 	if resp.Body != nil {
-		bodyBytes, err = io.ReadAll(resp.Body)
+		bodyBytes, err = s.readBody(resp.Body)
 		if err != nil {
+			traceDone(err)
 			log.Println("failed to read Body", err)
 			return nil, err
 		}
 	}
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		if bodyBytes != nil {
-			return nil, errors.ParseError(bodyBytes)
-		}
-		return nil, errors.Wrap(resp.Status)
+	traceDone(nil)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, errors.ParseHttpError(resp.StatusCode, resp.Status, bodyBytes)
+	}
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       bodyBytes,
+	}, nil
+}
+
+// reads the response body failing with ErrResponseTooLarge beyond the
+// configured limit, so that a misbehaving server or proxy streaming an
+// endless body does not exhaust the memory
+func (s *ecsSession) readBody(r io.Reader) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, s.maxResponseBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > s.maxResponseBytes {
+		return nil, errors.ErrResponseTooLarge
 	}
-	return bodyBytes, nil
+	return body, nil
 }
 
 // internal function to perform login while client is created using user
 // credentials. upon successful login attempt this updates the token that
-// is used as part of various api triggers
-func (s *ecsSession) performLogin() error {
+// is used as part of various api triggers and returns the max age of the
+// token in seconds, zero if ECS did not provide one
+func (s *ecsSession) performLogin() (int64, error) {
+	s.loginMu.Lock()
+	defer s.loginMu.Unlock()
+	token, age, err := s.login(s.getEndpoint())
+	if err != nil {
+		return 0, err
+	}
+	s.setToken(token, time.Duration(age)*time.Second)
+	return age, nil
+}
+
+// performs login against the endpoint, providing the token along with its
+// max age in seconds. callers must hold loginMu
+func (s *ecsSession) login(endpoint string) (string, int64, error) {
 	// token endpoint as of now is static and available at sub-path
 	// /login
-	req, err := http.NewRequest("GET", s.Endpoint+"/login", nil)
+	req, err := http.NewRequestWithContext(s.ctx, "GET", endpoint+"/login", nil)
+	if err != nil {
+		return "", 0, err
+	}
+	s.setCommonHeaders(req)
 	req.SetBasicAuth(s.Username, s.Password)
+	req, traceDone := s.traceRequest(req)
 	resp, err := s.c.Do(req)
+	traceDone(err)
 	if err != nil {
 		log.Println(err)
-		return err
+		return "", 0, err
 	}
 	defer func() {
 		if resp.Body != nil {
@@ -157,42 +489,271 @@ func (s *ecsSession) performLogin() error {
 		}
 	}()
 	if resp.StatusCode != http.StatusOK {
-		return errors.Wrap("login request failed, check endpoint or credentials")
+		return "", 0, errors.ParseHttpError(resp.StatusCode, "login request failed, check endpoint or credentials", nil)
 	}
 	token := ""
+	age := int64(0)
 	if len(resp.Header) != 0 {
-		token = resp.Header.Get("X-SDS-AUTH-TOKEN")
+		token = lookupHeader(resp.Header, s.tokenHeader)
 		maxAge := resp.Header.Get("X-SDS-AUTH-MAX-AGE")
 		if maxAge != "" && token != "" {
+			// only the age is logged, token value must never be logged
 			log.Println("got token age", maxAge)
-			age, err := strconv.ParseInt(maxAge, 10, 64)
+			age, err = strconv.ParseInt(maxAge, 10, 64)
 			if err != nil {
 				log.Println("invalid age received", err)
-			} else {
-				go func() {
-					// trigger token refresh upon approaching token age
-					if age > TimeBufferInSeconds {
-						age = age - TimeBufferInSeconds
-					}
-					time.Sleep(time.Duration(age) * time.Second)
-					err := s.performLogin()
-					if err != nil {
-						// TODO(Prabhjot) need to evaluate if this situation
-						// can be handled gracefully
-						log.Fatalln("failed to refresh the session token")
-					}
-				}()
+				age = 0
 			}
 		}
 	}
 	if token != "" {
-		s.Token = token
+		return token, age, nil
+	}
+	return "", 0, errors.Wrap("Auth Token not available in response")
+}
+
+// sets the auth token on the request, header name is used as configured
+// without canonicalizing it for gateways expecting a specific casing
+func (s *ecsSession) setTokenHeader(req *http.Request) {
+	for k := range req.Header {
+		if strings.EqualFold(k, s.tokenHeader) {
+			delete(req.Header, k)
+		}
+	}
+	req.Header[s.tokenHeader] = []string{s.getToken()}
+}
+
+// applies the configured redirect policy, by default upto
+// maxRedirects redirects are followed same as http.Client. auth token is
+// set on the redirected request if the original one carried it, since
+// http.Client may not forward it on redirects to another host, eg. a load
+// balancer redirecting to a node
+func (s *ecsSession) redirectPolicy(req *http.Request, via []*http.Request) error {
+	if s.checkRedirect != nil {
+		if err := s.checkRedirect(req, via); err != nil {
+			return err
+		}
+	} else if len(via) >= maxRedirects {
+		return errors.Wrap("stopped after " + strconv.Itoa(maxRedirects) + " redirects")
+	}
+	if lookupHeader(via[0].Header, s.tokenHeader) != "" {
+		s.setTokenHeader(req)
+	}
+	return nil
+}
+
+// gets value of the header matching name case insensitively, header
+// names in response may not be canonical when rewritten by gateways
+func lookupHeader(h http.Header, name string) string {
+	if v := h.Get(name); v != "" {
+		return v
+	}
+	for k, v := range h {
+		if strings.EqualFold(k, name) && len(v) != 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+func (s *ecsSession) getEndpoint() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Endpoint
+}
+
+func (s *ecsSession) getToken() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Token
+}
+
+func (s *ecsSession) setToken(token string, maxAge time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Token = token
+	s.tokenIssuedAt = s.clock.Now()
+	s.tokenMaxAge = maxAge
+}
+
+// time elapsed since the current token was obtained
+func (s *ecsSession) TokenAge() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clock.Now().Sub(s.tokenIssuedAt)
+}
+
+// time left till the current token expires, zero once the token has
+// expired or if ECS did not provide the token age. a ttl approaching zero
+// indicates failing token refresh
+func (s *ecsSession) TokenTTL() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.tokenMaxAge == 0 {
+		return 0
+	}
+	ttl := s.tokenMaxAge - s.clock.Now().Sub(s.tokenIssuedAt)
+	if ttl < 0 {
+		return 0
+	}
+	return ttl
+}
+
+// keeps refreshing the token upon approaching its age, till the session
+// is closed or the refresh fails even after retries
+func (s *ecsSession) refreshLoop(age int64) {
+	for age > 0 {
+		// trigger token refresh upon approaching token age
+		if age > TimeBufferInSeconds {
+			age = age - TimeBufferInSeconds
+		}
+		select {
+		case <-s.ctx.Done():
+			return
+		case age = <-s.refreshed:
+			// token was refreshed manually, reschedule as per its age
+			continue
+		case <-s.clock.After(time.Duration(age) * time.Second):
+		}
+		var err error
+		age, err = s.refreshWithRetry()
+		if err != nil {
+			if s.ctx.Err() != nil {
+				// session closed while refreshing
+				return
+			}
+			log.Println("failed to refresh the session token, giving up", err)
+			if s.onRefreshFailure != nil {
+				s.onRefreshFailure(err)
+			}
+			return
+		}
+	}
+}
+
+// performs login to refresh the token, retrying with backoff upon
+// transient failures
+func (s *ecsSession) refreshWithRetry() (int64, error) {
+	var err error
+	for attempt := 0; attempt < s.refreshAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-s.ctx.Done():
+				return 0, s.ctx.Err()
+			case <-s.clock.After(backoff(attempt-1, refreshBaseBackoff, refreshMaxBackoff)):
+			}
+		}
+		var age int64
+		age, err = s.performLogin()
+		if err == nil {
+			return age, nil
+		}
+		log.Println("failed to refresh the session token", err)
+		if !isRetryable(err) {
+			break
+		}
+	}
+	return 0, err
+}
+
+// performs login again to obtain a fresh token, background refresh if
+// enabled is rescheduled as per the age of the new token
+func (s *ecsSession) Refresh() error {
+	age, err := s.performLogin()
+	if err != nil {
+		return err
+	}
+	s.rescheduleRefresh(age)
+	return nil
+}
+
+// notifies background refresh, if enabled, about the age of the token
+// obtained outside of it
+func (s *ecsSession) rescheduleRefresh(age int64) {
+	if s.autoRefresh {
+		// keep only the latest age if background refresh has not yet
+		// consumed the previous one
+		select {
+		case <-s.refreshed:
+		default:
+		}
+		select {
+		case s.refreshed <- age:
+		default:
+		}
+	}
+}
+
+// invalidates the token of the session on ECS
+func (s *ecsSession) logout() error {
+	_, err := s.Get("/logout", nil, nil)
+	return err
+}
+
+// stops the token refresh, session is not usable once closed
+func (s *ecsSession) Close() error {
+	s.cancel()
+	return nil
+}
+
+// stops the token refresh and rejects new requests with ErrClientClosed,
+// waiting for the requests in flight to complete till ctx is done
+func (s *ecsSession) Shutdown(ctx context.Context) error {
+	s.closeMu.Lock()
+	s.closed = true
+	s.closeMu.Unlock()
+	s.cancel()
+
+	drained := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
 		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// registers a request in flight, fails once the session is shut down.
+// returned func must be called on completion of the request
+func (s *ecsSession) startRequest() (func(), error) {
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+	if s.closed {
+		return nil, errors.ErrClientClosed
 	}
-	return errors.Wrap("Auth Token not available in response")
+	s.inflight.Add(1)
+	return s.inflight.Done, nil
 }
 
-func createEcsSession(username, password, endpoint string) (*ecsSession, error) {
+// records error of an invalid option, first one is kept
+func (s *ecsSession) setOptErr(err error) {
+	if s.optErr == nil {
+		s.optErr = err
+	}
+}
+
+func createEcsSession(username, password, endpoint string, opts ...Option) (*ecsSession, error) {
+	s, err := newEcsSession(username, password, endpoint, opts...)
+	if err != nil {
+		return nil, err
+	}
+	age, err := s.performLogin()
+	if err != nil {
+		s.cancel()
+		return nil, err
+	}
+	if s.autoRefresh {
+		go s.refreshLoop(age)
+	}
+	return s, nil
+}
+
+// sets up the session as per the options without logging in
+func newEcsSession(username, password, endpoint string, opts ...Option) (*ecsSession, error) {
 	// since certificate might be self signed, with mostly internal
 	// communication with Dell ECS storage, it is safe to ignore
 	// certificate validation
@@ -200,14 +761,49 @@ func createEcsSession(username, password, endpoint string) (*ecsSession, error)
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 	}
 	s := &ecsSession{
-		Username: username,
-		Password: password,
-		Endpoint: endpoint,
-		c:        &http.Client{Transport: tr},
+		Username:         username,
+		Password:         password,
+		Endpoint:         endpoint,
+		c:                &http.Client{},
+		refreshAttempts:  DefaultRefreshAttempts,
+		autoRefresh:      true,
+		userAgent:        DefaultUserAgent,
+		tokenHeader:      DefaultTokenHeader,
+		dataPort:         DefaultDataPort,
+		maxResponseBytes: DefaultMaxResponseBytes,
+		minTLSVersion:    DefaultMinTLSVersion,
+		refreshed:        make(chan int64, 1),
+		clock:            realClock{},
 	}
-	err := s.performLogin()
-	if err != nil {
-		return nil, err
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.optErr != nil {
+		return nil, s.optErr
+	}
+	tr.TLSClientConfig.MinVersion = s.minTLSVersion
+	tr.DisableKeepAlives = s.disableKeepAlives
+	if s.dialContext != nil {
+		tr.Proxy = nil
+		tr.DialContext = s.dialContext
+	}
+	for _, fn := range s.transportFuncs {
+		fn(tr)
+	}
+	s.transport = newReloadableTransport(tr)
+	s.c.Transport = s.transport
+	s.c.CheckRedirect = s.redirectPolicy
+	if s.mgmtPort != "" {
+		ep, err := endpointWithPort(s.Endpoint, s.mgmtPort)
+		if err != nil {
+			return nil, err
+		}
+		s.Endpoint = ep
+	}
+	if s.dataEndpoint == "" {
+		s.dataEndpoint = deriveDataEndpoint(s.Endpoint, s.dataPort)
+		s.dataEndpointDerived = true
 	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
 	return s, nil
 }
@@ -0,0 +1,386 @@
+package goecsclient
+
+import (
+	"context"
+	"crypto/tls"
+	stderrors "errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coredgeio/goecsclient/errors"
+)
+
+func TestDoPreviewOnly(t *testing.T) {
+	srv := newTestServer(t, DefaultTokenHeader)
+	var previewed []string
+	c, err := CreateEcsClientWithUserCred("user", "pass", srv.URL,
+		WithPreviewOnly(true),
+		WithDryRun(func(req *http.Request) {
+			previewed = append(previewed, req.Method+" "+req.URL.Path)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	req, err := http.NewRequest("POST", "/object/bucket", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Do(req)
+	if !stderrors.Is(err, errors.ErrPreviewOnly) {
+		t.Fatalf("expected ErrPreviewOnly, got %v", err)
+	}
+	if resp != nil {
+		t.Fatal("expected no response in preview only mode")
+	}
+	if len(previewed) != 1 || previewed[0] != "POST /object/bucket" {
+		t.Fatalf("unexpected previewed requests %v", previewed)
+	}
+}
+
+// meant to be run with -race, hammers a shared client with requests while
+// the token gets refreshed in background and manually and tls config is
+// reloaded
+func TestConcurrentUse(t *testing.T) {
+	var logins atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			logins.Add(1)
+			w.Header().Set(DefaultTokenHeader, testToken)
+			// short age makes background refresh fire during the test
+			w.Header().Set("X-SDS-AUTH-MAX-AGE", "1")
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	c, err := CreateEcsClientWithUserCred("user", "pass", srv.URL, WithRetries(2), WithRetryBudget(0.1, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	deadline := time.Now().Add(1500 * time.Millisecond)
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for time.Now().Before(deadline) {
+				if _, err := c.Get("/object/bucket", nil, nil); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := c.Post("/object/bucket", []byte("{}"), nil, nil); err != nil {
+					t.Error(err)
+					return
+				}
+				c.TokenTTL()
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for time.Now().Before(deadline) {
+			if err := c.Refresh(); err != nil {
+				t.Error(err)
+				return
+			}
+			if err := c.ReloadTLS(&tls.Config{InsecureSkipVerify: true}); err != nil {
+				t.Error(err)
+				return
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}()
+	wg.Wait()
+	if logins.Load() < 2 {
+		t.Fatalf("expected token to be refreshed, got %d logins", logins.Load())
+	}
+}
+
+func TestShutdownDrainsRequests(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			w.Header().Set(DefaultTokenHeader, testToken)
+			return
+		}
+		close(started)
+		<-release
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	c, err := CreateEcsClientWithUserCred("user", "pass", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqErr := make(chan error, 1)
+	go func() {
+		_, err := c.Get("/object/bucket", nil, nil)
+		reqErr <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.Shutdown(ctx); !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected shutdown to time out waiting for request, got %v", err)
+	}
+	if _, err := c.Get("/object/bucket", nil, nil); !stderrors.Is(err, errors.ErrClientClosed) {
+		t.Fatalf("expected ErrClientClosed, got %v", err)
+	}
+
+	close(release)
+	if err := <-reqErr; err != nil {
+		t.Fatalf("request in flight failed: %v", err)
+	}
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithContextDeadline(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			w.Header().Set(DefaultTokenHeader, testToken)
+		} else {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	c, err := CreateEcsClientWithUserCred("user", "pass", srv.URL, WithTimeout(5*time.Second), WithRetries(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = c.WithContext(ctx).Get("/object/bucket", nil, nil)
+	if !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Fatalf("request not bound by context deadline, took %v", elapsed)
+	}
+	// client wide timeout still allows the slow request without context
+	if _, err = c.Get("/object/bucket", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	srv := newTestServer(t, DefaultTokenHeader)
+	dump := &syncBuffer{}
+	c, err := CreateEcsClientWithUserCred("user", "pass", srv.URL, WithMaxResponseBytes(1), WithDebugDump(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err = c.Get("/object/bucket", nil, nil); !stderrors.Is(err, errors.ErrResponseTooLarge) {
+		t.Fatalf("expected ErrResponseTooLarge, got %v", err)
+	}
+	if !strings.Contains(dump.String(), "not dumped") {
+		t.Fatalf("expected body beyond limit to be left out of dump:\n%s", dump.String())
+	}
+	// responses of the data api read by the s3 client
+	if _, err = c.ReadBody(strings.NewReader("{}")); !stderrors.Is(err, errors.ErrResponseTooLarge) {
+		t.Fatalf("expected ErrResponseTooLarge reading data api response, got %v", err)
+	}
+}
+
+func TestRedirectKeepsToken(t *testing.T) {
+	target := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(DefaultTokenHeader) != testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer target.Close()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			w.Header().Set(DefaultTokenHeader, testToken)
+			w.Write([]byte("{}"))
+			return
+		}
+		http.Redirect(w, r, target.URL+r.URL.Path, http.StatusTemporaryRedirect)
+	}))
+	defer srv.Close()
+
+	var redirects int
+	c, err := CreateEcsClientWithUserCred("user", "pass", srv.URL,
+		WithCheckRedirect(func(req *http.Request, via []*http.Request) error {
+			// drop the token so the client has to set it again
+			req.Header.Del(DefaultTokenHeader)
+			redirects++
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err = c.Get("/object/bucket", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if redirects != 1 {
+		t.Fatalf("expected redirect policy to be consulted once, got %d", redirects)
+	}
+}
+
+func TestCheckEndpoint(t *testing.T) {
+	srv := newTestServer(t, DefaultTokenHeader)
+	if err := CheckEndpoint(context.Background(), srv.URL); err != nil {
+		t.Fatalf("expected reachable endpoint, got %v", err)
+	}
+
+	closed := httptest.NewTLSServer(http.NotFoundHandler())
+	closed.Close()
+	err := CheckEndpoint(context.Background(), closed.URL)
+	if !stderrors.Is(err, errors.ErrEndpointUnreachable) {
+		t.Fatalf("expected ErrEndpointUnreachable, got %v", err)
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	var conns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(DefaultTokenHeader, testToken)
+		w.Write([]byte("{}"))
+	}))
+	srv.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	c, err := CreateEcsClientWithUserCred("user", "pass", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	before := conns.Load()
+	if err = c.Warmup(context.Background(), 5); err != nil {
+		t.Fatal(err)
+	}
+	// capped to the idle connections retained per host
+	opened := conns.Load() - before
+	if opened == 0 || opened > http.DefaultMaxIdleConnsPerHost {
+		t.Fatalf("expected 1 to %d connections, got %d", http.DefaultMaxIdleConnsPerHost, opened)
+	}
+}
+
+func TestSetEndpoint(t *testing.T) {
+	var oldHits, newHits int32
+	old := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&oldHits, 1)
+		w.Header().Set(DefaultTokenHeader, testToken)
+		w.Write([]byte("{}"))
+	}))
+	defer old.Close()
+	cutover := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			w.Header().Set(DefaultTokenHeader, "new-token")
+		} else if r.Header.Get(DefaultTokenHeader) != "new-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		atomic.AddInt32(&newHits, 1)
+		w.Write([]byte("{}"))
+	}))
+	defer cutover.Close()
+
+	c, err := CreateEcsClientWithUserCred("user", "pass", old.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err = c.SetEndpoint("not a url"); err == nil {
+		t.Fatal("expected invalid endpoint to be rejected")
+	}
+	closed := httptest.NewTLSServer(http.NotFoundHandler())
+	closed.Close()
+	if err = c.SetEndpoint(closed.URL); err == nil {
+		t.Fatal("expected failing login to be reported")
+	}
+	if _, err = c.Get("/object/bucket", nil, nil); err != nil {
+		t.Fatalf("expected client to keep the old endpoint, got %v", err)
+	}
+
+	oldBefore := atomic.LoadInt32(&oldHits)
+	if err = c.SetEndpoint(cutover.URL + "/"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.Get("/object/bucket", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&oldHits); got != oldBefore {
+		t.Fatalf("expected no requests to old endpoint after cutover, got %d", got-oldBefore)
+	}
+	if got := atomic.LoadInt32(&newHits); got != 2 {
+		t.Fatalf("expected login and request against new endpoint, got %d", got)
+	}
+}
+
+func TestCreateConflictOnRetry(t *testing.T) {
+	var creates int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(DefaultTokenHeader, testToken)
+		if r.URL.Path == "/login" {
+			w.Write([]byte("{}"))
+			return
+		}
+		// first attempt creates the resource but its response is lost
+		if atomic.AddInt32(&creates, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"code":1004,"description":"bucket already exists"}`))
+	}))
+	defer srv.Close()
+	c, err := CreateEcsClientWithUserCred("user", "pass", srv.URL, WithRetries(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	resp, err := c.Create("/object/bucket", []byte("{}"), nil, nil)
+	if resp != nil {
+		t.Fatalf("expected no response for conflicting create, got %+v", resp)
+	}
+	if !stderrors.Is(err, errors.ErrCreatedByEarlierAttempt) || !stderrors.Is(err, errors.ErrAlreadyExists) {
+		t.Fatalf("expected ErrCreatedByEarlierAttempt and ErrAlreadyExists, got %v", err)
+	}
+	if got := atomic.LoadInt32(&creates); got != 2 {
+		t.Fatalf("expected create to be retried once, got %d attempts", got)
+	}
+
+	// conflict on the first attempt is a plain already exists
+	_, err = c.Create("/object/bucket", []byte("{}"), nil, nil)
+	if !stderrors.Is(err, errors.ErrAlreadyExists) || stderrors.Is(err, errors.ErrCreatedByEarlierAttempt) {
+		t.Fatalf("expected only ErrAlreadyExists, got %v", err)
+	}
+}
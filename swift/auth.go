@@ -0,0 +1,72 @@
+package swift
+
+import (
+	"crypto/tls"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/coredgeio/goecsclient/errors"
+)
+
+// token issued by the Swift compatible object api of ECS
+type SwiftToken struct {
+	// auth token to be sent as X-Auth-Token on Swift requests
+	Token string
+	// url of the account storage to be used for Swift requests
+	StorageUrl string
+}
+
+// same as management api, data endpoint certificates are mostly self
+// signed. shared across calls so that its connections are reused instead
+// of a transport with idle connections being left behind on every call
+var defaultClient = &http.Client{
+	Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	},
+}
+
+// authenticates against the Swift v1.0 auth api of ECS data endpoint, eg.
+// https://ecs-data:9025, using object user and its Swift password.
+// certificate of the endpoint is not verified, use SwiftAuthWithClient
+// to supply a client verifying it.
+//
+// this is independent of the management api session and does not use or
+// affect the management auth token
+func SwiftAuth(endpoint, user, key string) (*SwiftToken, error) {
+	return SwiftAuthWithClient(defaultClient, endpoint, user, key)
+}
+
+// same as SwiftAuth using the given http client, eg. one with a tls
+// config trusting the CA of ECS, or HTTPClient of the management client
+// to share its transport settings
+func SwiftAuthWithClient(c *http.Client, endpoint, user, key string) (*SwiftToken, error) {
+	req, err := http.NewRequest("GET", strings.TrimRight(endpoint, "/")+"/auth/v1.0", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Auth-User", user)
+	req.Header.Set("X-Auth-Key", key)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer func() {
+		if resp.Body != nil {
+			resp.Body.Close()
+		}
+	}()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return nil, errors.ParseHttpError(resp.StatusCode, "swift auth request failed, check endpoint or credentials", nil)
+	}
+	token := &SwiftToken{
+		Token:      resp.Header.Get("X-Auth-Token"),
+		StorageUrl: resp.Header.Get("X-Storage-Url"),
+	}
+	if token.Token == "" {
+		return nil, errors.Wrap("Auth Token not available in response")
+	}
+	return token, nil
+}
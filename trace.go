@@ -0,0 +1,73 @@
+package goecsclient
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+)
+
+// timings of the phases of a request, phases which did not happen for
+// the request, eg. DNS and connect on a reused connection, are zero
+type TraceInfo struct {
+	Method string
+	URL    string
+
+	DNS          time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+	// time from start of request till first byte of response
+	TimeToFirstByte time.Duration
+	// time from start of request till response body is read
+	Total time.Duration
+
+	ConnReused bool
+	// error if the request failed before a response was received
+	Err error
+}
+
+// installs client trace on the request, returned function reports the
+// collected timings and must be called once the request is complete
+func (s *ecsSession) traceRequest(req *http.Request) (*http.Request, func(err error)) {
+	if s.clientTrace == nil {
+		return req, func(error) {}
+	}
+	info := TraceInfo{
+		Method: req.Method,
+		URL:    req.URL.Redacted(),
+	}
+	var dnsStart, connectStart, tlsStart time.Time
+	start := time.Now()
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			info.DNS = time.Since(dnsStart)
+		},
+		ConnectStart: func(network, addr string) {
+			connectStart = time.Now()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			info.Connect = time.Since(connectStart)
+		},
+		TLSHandshakeStart: func() {
+			tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			info.TLSHandshake = time.Since(tlsStart)
+		},
+		GotConn: func(ci httptrace.GotConnInfo) {
+			info.ConnReused = ci.Reused
+		},
+		GotFirstResponseByte: func() {
+			info.TimeToFirstByte = time.Since(start)
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	return req, func(err error) {
+		info.Total = time.Since(start)
+		info.Err = err
+		s.clientTrace(info)
+	}
+}
@@ -0,0 +1,112 @@
+package goecsclient
+
+import (
+	"context"
+	"crypto/tls"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/coredgeio/goecsclient/errors"
+)
+
+// transport of the session which can be replaced while requests are in
+// flight, eg. on certificate rotation. requests started before the swap
+// complete on the transport they started with
+type reloadableTransport struct {
+	tr atomic.Pointer[http.Transport]
+	// serializes reloads, so that concurrent reloads do not lose one of
+	// the configs or leave the connections of a transport open
+	reloadMu sync.Mutex
+}
+
+func newReloadableTransport(tr *http.Transport) *reloadableTransport {
+	t := &reloadableTransport{}
+	t.tr.Store(tr)
+	return t
+}
+
+func (t *reloadableTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.tr.Load().RoundTrip(req)
+}
+
+func (t *reloadableTransport) CloseIdleConnections() {
+	t.tr.Load().CloseIdleConnections()
+}
+
+// replaces the tls config of the transport, connections of the previous
+// transport are closed once idle so new requests use the new config
+func (t *reloadableTransport) reloadTLS(cfg *tls.Config) {
+	t.reloadMu.Lock()
+	defer t.reloadMu.Unlock()
+	old := t.tr.Load()
+	tr := old.Clone()
+	tr.TLSClientConfig = cfg.Clone()
+	t.tr.Store(tr)
+	old.CloseIdleConnections()
+}
+
+// replaces the tls config used for api requests, eg. after rotation of
+// client certificate or CA bundle, without creating a new session. the
+// token and its background refresh are retained, requests in flight
+// complete using the previous config. min tls version configured for the
+// session applies when cfg does not set one
+func (s *ecsSession) ReloadTLS(cfg *tls.Config) error {
+	if cfg == nil {
+		return errors.Wrap("tls config is required")
+	}
+	if cfg.MinVersion == 0 {
+		cfg = cfg.Clone()
+		cfg.MinVersion = s.minTLSVersion
+	}
+	s.transport.reloadTLS(cfg)
+	return nil
+}
+
+// opens upto n connections to the management endpoint ahead of the first
+// requests, so that they do not pay for connection and tls setup. n is
+// capped to the idle connections the transport retains per host, since
+// connections beyond those would be closed right away. connections are
+// primed using concurrent HEAD requests, responses are ignored as the
+// connection being established is what matters
+func (s *ecsSession) Warmup(ctx context.Context, n int) error {
+	tr := s.transport.tr.Load()
+	if s.disableKeepAlives || tr.DisableKeepAlives || n <= 0 {
+		return nil
+	}
+	maxIdle := tr.MaxIdleConnsPerHost
+	if maxIdle <= 0 {
+		maxIdle = http.DefaultMaxIdleConnsPerHost
+	}
+	if tr.MaxConnsPerHost > 0 && tr.MaxConnsPerHost < maxIdle {
+		maxIdle = tr.MaxConnsPerHost
+	}
+	if n > maxIdle {
+		n = maxIdle
+	}
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req, err := http.NewRequestWithContext(ctx, "HEAD", s.getEndpoint()+"/", nil)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			s.setCommonHeaders(req)
+			resp, err := s.c.Do(req)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}(i)
+	}
+	wg.Wait()
+	return stderrors.Join(errs...)
+}
@@ -0,0 +1,116 @@
+package goecsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/coredgeio/goecsclient/errors"
+)
+
+// version of the goecsclient package
+const Version = "0.1.0"
+
+// User-Agent sent on requests unless overridden using WithUserAgent
+const DefaultUserAgent = "goecsclient/" + Version
+
+// software version of ECS the client is talking to
+type EcsVersion struct {
+	// version of ECS software, eg. 3.6.0.0
+	Version string
+	// build of the ECS software, eg. 122606.fbg1d5f9ab
+	Build string
+}
+
+type nodeListResp struct {
+	Nodes []struct {
+		Version string `json:"version,omitempty"`
+		IsLocal bool   `json:"isLocal,omitempty"`
+	} `json:"node,omitempty"`
+}
+
+// provides the ECS version by querying the nodes of the cluster, version
+// reported by the local node is used. version is cached after first
+// successful fetch since it changes only when ECS gets upgraded
+func (s *ecsSession) GetVersion() (*EcsVersion, error) {
+	s.versionMu.Lock()
+	defer s.versionMu.Unlock()
+	if s.ecsVersion != nil {
+		v := *s.ecsVersion
+		return &v, nil
+	}
+
+	bytes, err := s.Get("/vdc/nodes", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp := &nodeListResp{}
+	if err = json.Unmarshal(bytes, resp); err != nil {
+		log.Println("failed to decode response for get nodes", err)
+		return nil, err
+	}
+	if len(resp.Nodes) == 0 {
+		return nil, errors.Wrap("no nodes reported by ECS")
+	}
+	full := resp.Nodes[0].Version
+	for _, n := range resp.Nodes {
+		if n.IsLocal {
+			full = n.Version
+			break
+		}
+	}
+	// full version is of the form 3.6.0.0.122606.fbg1d5f9ab
+	v := &EcsVersion{Version: full}
+	if parts := strings.SplitN(full, ".", 5); len(parts) == 5 {
+		v.Version = strings.Join(parts[:4], ".")
+		v.Build = parts[4]
+	}
+	s.ecsVersion = v
+	ret := *v
+	return &ret, nil
+}
+
+// fails with ErrUnsupported if ECS behind the session is older than min,
+// for gating operations on the ECS version. version of a session not
+// providing it, eg. a fake, cannot be verified and is reported as
+// unsupported as well, rather than sending a request ECS may reject
+func RequireVersion(s Session, min string) error {
+	v, ok := s.(interface {
+		GetVersion() (*EcsVersion, error)
+	})
+	if !ok {
+		return fmt.Errorf("%w: version of ECS not known", errors.ErrUnsupported)
+	}
+	version, err := v.GetVersion()
+	if err != nil {
+		return err
+	}
+	if !version.AtLeast(min) {
+		return fmt.Errorf("%w: requires %s, have %s", errors.ErrUnsupported, min, version.Version)
+	}
+	return nil
+}
+
+// true when the version is same or newer than v, both of the dotted form
+// eg. 3.6.0.0, missing trailing components are treated as zero
+func (e *EcsVersion) AtLeast(v string) bool {
+	have := strings.Split(e.Version, ".")
+	want := strings.Split(v, ".")
+	for i := 0; i < len(have) || i < len(want); i++ {
+		h, w := versionPart(have, i), versionPart(want, i)
+		if h != w {
+			return h > w
+		}
+	}
+	return true
+}
+
+func versionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	n, _ := strconv.Atoi(parts[i])
+	return n
+}
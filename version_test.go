@@ -0,0 +1,64 @@
+package goecsclient
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coredgeio/goecsclient/errors"
+)
+
+func TestEcsVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		have, want string
+		expected   bool
+	}{
+		{"3.6.0.0", "3.6.0.0", true},
+		{"3.6.1.0", "3.6.0.0", true},
+		{"3.10.0.0", "3.9.0.0", true},
+		{"3.5.2.1", "3.6", false},
+		{"3.6", "3.6.0.0", true},
+		{"2.2.1.0", "3.5.0.0", false},
+	}
+	for _, tt := range tests {
+		v := &EcsVersion{Version: tt.have}
+		if got := v.AtLeast(tt.want); got != tt.expected {
+			t.Errorf("%s at least %s: expected %v, got %v", tt.have, tt.want, tt.expected, got)
+		}
+	}
+}
+
+// session providing only the requests, without the version of ECS
+type versionlessSession struct {
+	Session
+}
+
+func TestRequireVersion(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(DefaultTokenHeader, testToken)
+		if r.URL.Path == "/vdc/nodes" {
+			w.Write([]byte(`{"node":[{"version":"3.5.0.0.100.abc","isLocal":true}]}`))
+			return
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+	c, err := CreateEcsClientWithUserCred("user", "pass", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	scoped := c.WithContext(context.Background())
+	if err = RequireVersion(scoped, "3.5.0.0"); err != nil {
+		t.Fatalf("expected version to be supported, got %v", err)
+	}
+	if err = RequireVersion(scoped, "3.6.0.0"); !stderrors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported for older ECS, got %v", err)
+	}
+	if err = RequireVersion(versionlessSession{c}, "3.5.0.0"); !stderrors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported when version is not known, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package goecsclient
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/coredgeio/goecsclient/errors"
+)
+
+// types of WatchEvent
+const (
+	WatchAdded   = "ADDED"
+	WatchUpdated = "UPDATED"
+	WatchDeleted = "DELETED"
+	// fetch failed, polling continues on the next interval
+	WatchError = "ERROR"
+)
+
+// change observed by Watch, Key identifies the item which changed. Object
+// is the new state of the item, or the last known state for deletes
+type WatchEvent struct {
+	Type   string
+	Key    string
+	Object interface{}
+	Err    error
+}
+
+// polls fetch every interval and emits the changes between successive
+// results, ECS having no native watch. a map with string keys returned
+// by fetch, eg. buckets keyed by name, is diffed per entry. any other
+// result is treated as a single item with empty key, a nil result
+// meaning the item does not exist. items are compared using
+// reflect.DeepEqual.
+//
+// first fetch is made right away with all the items reported as added.
+// channel is closed once ctx is done, events not consumed by then are
+// dropped
+func Watch(ctx context.Context, fetch func() (interface{}, error), interval time.Duration) (<-chan WatchEvent, error) {
+	if fetch == nil {
+		return nil, errors.Wrap("fetch is required")
+	}
+	if interval <= 0 {
+		return nil, errors.Wrap("watch interval must be positive")
+	}
+	events := make(chan WatchEvent)
+	go func() {
+		defer close(events)
+		send := func(ev WatchEvent) bool {
+			select {
+			case events <- ev:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var prev map[string]interface{}
+		for {
+			obj, err := fetch()
+			if err != nil {
+				if !send(WatchEvent{Type: WatchError, Err: err}) {
+					return
+				}
+			} else {
+				cur := watchItems(obj)
+				for _, ev := range diffWatchItems(prev, cur) {
+					if !send(ev) {
+						return
+					}
+				}
+				prev = cur
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// splits the fetched result into items keyed by their key
+func watchItems(obj interface{}) map[string]interface{} {
+	items := map[string]interface{}{}
+	if obj == nil {
+		return items
+	}
+	v := reflect.ValueOf(obj)
+	if v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String {
+		iter := v.MapRange()
+		for iter.Next() {
+			items[iter.Key().String()] = iter.Value().Interface()
+		}
+		return items
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Map || v.Kind() == reflect.Slice) && v.IsNil() {
+		return items
+	}
+	items[""] = obj
+	return items
+}
+
+// events in sorted key order, deletes after adds and updates
+func diffWatchItems(prev, cur map[string]interface{}) []WatchEvent {
+	var events []WatchEvent
+	for _, k := range sortedKeys(cur) {
+		old, ok := prev[k]
+		switch {
+		case !ok:
+			events = append(events, WatchEvent{Type: WatchAdded, Key: k, Object: cur[k]})
+		case !reflect.DeepEqual(old, cur[k]):
+			events = append(events, WatchEvent{Type: WatchUpdated, Key: k, Object: cur[k]})
+		}
+	}
+	for _, k := range sortedKeys(prev) {
+		if _, ok := cur[k]; !ok {
+			events = append(events, WatchEvent{Type: WatchDeleted, Key: k, Object: prev[k]})
+		}
+	}
+	return events
+}
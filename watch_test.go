@@ -0,0 +1,61 @@
+package goecsclient
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	results := []map[string]int{
+		{"a": 1, "b": 1},
+		{"a": 1, "b": 1},
+		{"a": 2, "b": 1},
+		{"a": 2, "c": 1},
+	}
+	calls := 0
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := Watch(ctx, func() (interface{}, error) {
+		r := results[len(results)-1]
+		if calls < len(results) {
+			r = results[calls]
+		}
+		calls++
+		return r, nil
+	}, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []WatchEvent{
+		{Type: WatchAdded, Key: "a"},
+		{Type: WatchAdded, Key: "b"},
+		{Type: WatchUpdated, Key: "a"},
+		{Type: WatchAdded, Key: "c"},
+		{Type: WatchDeleted, Key: "b"},
+	}
+	for _, want := range expected {
+		select {
+		case ev := <-events:
+			if ev.Type != want.Type || ev.Key != want.Key {
+				t.Fatalf("expected %s %s, got %s %s", want.Type, want.Key, ev.Type, ev.Key)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s %s", want.Type, want.Key)
+		}
+	}
+
+	cancel()
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("events not closed on cancellation")
+		}
+	}
+}